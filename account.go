@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+	"github.com/willthefoollearn/chirpy/internal/mailer"
+)
+
+// accountDeletionGrace is how long a deleted account can still be restored
+// before it and everything in it is removed for good.
+const accountDeletionGrace = 30 * 24 * time.Hour
+
+// handlerDeleteAccount schedules the account for deletion and signs it out
+// everywhere. Logging back in during the grace period still works, so the
+// owner can change their mind with handlerRestoreAccount.
+func (cfg *apiConfig) handlerDeleteAccount(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Password string `json:"password"`
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parameters couldn't be decoded", err)
+		return
+	}
+
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	userID, err := cfg.authorize(req.Context(), accessToken, auth.ScopeAccount)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	user, err := cfg.db.GetUser(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "User couldn't be found", err)
+		return
+	}
+
+	ok := cfg.checkCurrentPassword(w, req, user, params.Password)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to delete account", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	user, err = qtx.ScheduleAccountDeletion(req.Context(), database.ScheduleAccountDeletionParams{
+		ID:          userID,
+		DeleteAfter: sql.NullTime{Time: time.Now().UTC().Add(accountDeletionGrace), Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to delete account", err)
+		return
+	}
+
+	err = qtx.RevokeAllUserSessions(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to delete account", err)
+		return
+	}
+
+	err = qtx.RevokeAllPersonalAccessTokens(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to delete account", err)
+		return
+	}
+
+	_, err = qtx.IncrementTokenVersion(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to delete account", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to delete account", err)
+		return
+	}
+
+	cfg.sendMail(mailer.Message{
+		To:      user.Email,
+		Subject: "Your Chirpy account will be deleted",
+		Body: fmt.Sprintf(
+			"Your account is scheduled to be deleted on %s. Until then you can log in and restore it. After that, your chirps, media and everything else in the account are gone for good.\n",
+			user.DeleteAfter.Time.Format("2 January 2006"),
+		),
+	})
+
+	respondWithJson(w, http.StatusAccepted, databaseUserToUser(user))
+}
+
+func (cfg *apiConfig) handlerRestoreAccount(w http.ResponseWriter, req *http.Request) {
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	userID, err := cfg.authorize(req.Context(), accessToken, auth.ScopeAccount)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	user, err := cfg.db.GetUser(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "User couldn't be found", err)
+		return
+	}
+	if !user.DeleteAfter.Valid {
+		respondWithError(w, http.StatusConflict, "Account isn't scheduled for deletion", nil)
+		return
+	}
+
+	user, err = cfg.db.CancelAccountDeletion(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to restore account", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, databaseUserToUser(user))
+}
+
+// deleteDueAccounts removes accounts whose grace period is over, one per
+// transaction. The database cascades the delete through the user's rows;
+// counters on other people's chirps are corrected first, and stored media
+// and exports are removed once the delete has committed.
+func (cfg *apiConfig) deleteDueAccounts(ctx context.Context) (int, error) {
+	deleted := 0
+	for {
+		userID, err := cfg.deleteDueAccount(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			return deleted, nil
+		}
+		if err != nil {
+			return deleted, err
+		}
+
+		log.Printf("Deleted account %s", userID)
+		deleted++
+	}
+}
+
+func (cfg *apiConfig) deleteDueAccount(ctx context.Context) (uuid.UUID, error) {
+	tx, err := cfg.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	userID, err := qtx.ClaimDueAccountDeletion(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	mediaKeys, err := qtx.ListUserStorageKeys(ctx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	exportKeys, err := qtx.ListUserExportKeys(ctx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	err = qtx.ReleaseUserLikes(ctx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	err = qtx.ReleaseUserReposts(ctx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	err = qtx.ReleaseUserReplies(ctx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	err = qtx.DeleteUser(ctx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	cfg.deleteStoredMedia(ctx, mediaKeys)
+	cfg.deleteStoredExports(ctx, exportKeys)
+
+	return userID, nil
+}
+
+func (cfg *apiConfig) runAccountReaper(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := cfg.deleteDueAccounts(context.Background())
+		if err != nil {
+			log.Printf("Unable to delete accounts: %v", err)
+		}
+
+		<-ticker.C
+	}
+}
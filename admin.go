@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+)
+
+type adminContextKey struct{}
+
+// adminFromContext returns the staff member requireRole let through.
+func adminFromContext(ctx context.Context) uuid.UUID {
+	admin, _ := ctx.Value(adminContextKey{}).(uuid.UUID)
+	return admin
+}
+
+// requireRole guards an /admin route. The caller needs an access token from
+// a password login, so a leaked personal access token can't reach the admin
+// tree, and at least the given role. The role is read from the database on
+// every request so a demotion takes effect straight away.
+func (cfg *apiConfig) requireRole(min auth.Role, next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		accessToken, err := auth.GetBearerToken(req.Header)
+		if err != nil {
+			respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+			return
+		}
+
+		principal, err := cfg.authenticate(req.Context(), accessToken)
+		if err != nil {
+			respondWithAuthError(w, "User can't be found with accessToken", err)
+			return
+		}
+		err = principal.Require(auth.ScopeAccount)
+		if err != nil {
+			respondWithAuthError(w, "User can't be found with accessToken", err)
+			return
+		}
+
+		role, err := cfg.db.GetUserRole(req.Context(), principal.UserID)
+		if err != nil {
+			respondWithError(w, http.StatusUnauthorized, "User can't be found with accessToken", err)
+			return
+		}
+		err = auth.Role(role).Require(min)
+		if err != nil {
+			respondWithAuthError(w, "User can't be found with accessToken", err)
+			return
+		}
+
+		ctx := context.WithValue(req.Context(), adminContextKey{}, principal.UserID)
+		next(w, req.WithContext(ctx))
+	})
+}
+
+func (cfg *apiConfig) handlerSetUserRole(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Role string `json:"role"`
+	}
+
+	userID, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	role, err := auth.ParseRole(params.Role)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	// Admins can't change their own role, so the last one can't lock
+	// everybody out by accident.
+	if userID == adminFromContext(req.Context()) {
+		respondWithError(w, http.StatusBadRequest, "Admins can't change their own role", nil)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to change role", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	user, err := qtx.SetUserRole(req.Context(), database.SetUserRoleParams{
+		ID:   userID,
+		Role: string(role),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Unable to find user", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to change role", err)
+		return
+	}
+
+	err = recordAudit(req.Context(), qtx, adminFromContext(req.Context()), "user.set_role", "user", user.ID.String(), string(role))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to change role", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to change role", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, databaseUserToUser(user))
+}
+
+// bootstrapAdmin is the break-glass way in: anyone who can reach the
+// database can make an existing account an admin, without needing an admin
+// to already exist. Run it as `chirpy bootstrap-admin <email>`.
+func bootstrapAdmin(ctx context.Context, db *database.Queries, email string) error {
+	user, err := db.SetUserRoleByEmail(ctx, database.SetUserRoleByEmailParams{
+		Email: email,
+		Role:  string(auth.RoleAdmin),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no user with email %s", email)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("User %s (%s) is now an admin", user.ID, user.Email)
+	return nil
+}
+
+// recordAudit appends a staff action to the moderation audit trail. Callers
+// pass their transaction's queries so the action and its record commit
+// together.
+func recordAudit(ctx context.Context, db *database.Queries, actor uuid.UUID, action, targetType, targetID, note string) error {
+	return db.RecordAuditEvent(ctx, database.RecordAuditEventParams{
+		ActorID:    actor,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Note:       note,
+	})
+}
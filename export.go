@@ -0,0 +1,472 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+	"github.com/willthefoollearn/chirpy/internal/export"
+	"github.com/willthefoollearn/chirpy/internal/storage"
+)
+
+const (
+	// Accounts up to this size are exported while the client waits. Larger
+	// ones are queued for the export worker.
+	inlineExportMaxChirps     = 1000
+	inlineExportMaxMediaBytes = 20 << 20
+
+	exportLifetime = 24 * time.Hour
+)
+
+type DataExport struct {
+	ID          uuid.UUID  `json:"id"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+func databaseExportToExport(dataExport database.DataExport) DataExport {
+	converted := DataExport{
+		ID:        dataExport.ID,
+		Status:    dataExport.Status,
+		CreatedAt: dataExport.CreatedAt,
+	}
+	if dataExport.CompletedAt.Valid {
+		converted.CompletedAt = &dataExport.CompletedAt.Time
+	}
+	if dataExport.ExpiresAt.Valid {
+		converted.ExpiresAt = &dataExport.ExpiresAt.Time
+	}
+
+	return converted
+}
+
+// setupExportStorage picks where finished exports are kept until they're
+// downloaded. They never share a store with media, since everything in the
+// media store is public. With MEDIA_STORAGE=s3 they go to S3_EXPORT_BUCKET
+// on the same service; otherwise they're kept on disk under EXPORT_DIR, which
+// defaults to the user's cache directory and must not be one /app/ serves.
+func (cfg *apiConfig) setupExportStorage() error {
+	if os.Getenv("MEDIA_STORAGE") == "s3" {
+		bucket := os.Getenv("S3_EXPORT_BUCKET")
+		if bucket == "" {
+			return errors.New("S3_EXPORT_BUCKET must be set when MEDIA_STORAGE=s3")
+		}
+
+		region := os.Getenv("S3_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+
+		store, err := storage.NewS3Store(
+			os.Getenv("S3_ENDPOINT"),
+			bucket,
+			region,
+			os.Getenv("S3_ACCESS_KEY"),
+			os.Getenv("S3_SECRET_KEY"),
+		)
+		if err != nil {
+			return err
+		}
+		cfg.exports = store
+		return nil
+	}
+
+	dir, err := privateDir("EXPORT_DIR", "exports")
+	if err != nil {
+		return err
+	}
+
+	store, err := storage.NewLocalStore(dir)
+	if err != nil {
+		return err
+	}
+	cfg.exports = store
+	return nil
+}
+
+// handlerExportData returns the user's data as a zip archive. Small accounts
+// get it straight away. Larger ones get 202 and a queued export, and the
+// same request returns the archive once it's ready.
+func (cfg *apiConfig) handlerExportData(w http.ResponseWriter, req *http.Request) {
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	user, err := cfg.authorize(req.Context(), accessToken, auth.ScopeAccount)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	latest, err := cfg.db.GetLatestDataExport(req.Context(), user)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusInternalServerError, "Unable to export data", err)
+		return
+	}
+	if err == nil {
+		switch latest.Status {
+		case "pending", "running":
+			respondWithExportQueued(w, latest)
+			return
+		case "ready":
+			if latest.ExpiresAt.Time.After(time.Now()) {
+				cfg.serveStoredExport(w, req, latest)
+				return
+			}
+		}
+	}
+
+	size, err := cfg.db.GetExportSize(req.Context(), user)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to export data", err)
+		return
+	}
+
+	if size.ChirpCount > inlineExportMaxChirps || size.MediaBytes > inlineExportMaxMediaBytes {
+		queued, err := cfg.db.CreateDataExport(req.Context(), user)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to export data", err)
+			return
+		}
+		respondWithExportQueued(w, queued)
+		return
+	}
+
+	file, fileSize, err := cfg.writeExportFile(req.Context(), user)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to export data", err)
+		return
+	}
+	defer removeExportFile(file)
+
+	writeExportHeaders(w, fileSize)
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, file)
+}
+
+func respondWithExportQueued(w http.ResponseWriter, dataExport database.DataExport) {
+	w.Header().Set("Retry-After", "30")
+	respondWithJson(w, http.StatusAccepted, databaseExportToExport(dataExport))
+}
+
+func (cfg *apiConfig) serveStoredExport(w http.ResponseWriter, req *http.Request, dataExport database.DataExport) {
+	body, object, err := cfg.exports.Get(req.Context(), dataExport.StorageKey.String)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve export", err)
+		return
+	}
+	defer body.Close()
+
+	writeExportHeaders(w, object.Size)
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, body)
+}
+
+func writeExportHeaders(w http.ResponseWriter, size int64) {
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="chirpy-export.zip"`)
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	w.Header().Set("Cache-Control", "no-store")
+}
+
+// writeExportFile builds a user's export in a temporary file, so an archive
+// full of media never has to fit in memory. The file is left at its start,
+// ready to be read; the caller removes it with removeExportFile.
+func (cfg *apiConfig) writeExportFile(ctx context.Context, userID uuid.UUID) (*os.File, int64, error) {
+	file, err := os.CreateTemp("", "chirpy-export-*.zip")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = cfg.writeExport(ctx, userID, file)
+	if err != nil {
+		removeExportFile(file)
+		return nil, 0, err
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		removeExportFile(file)
+		return nil, 0, err
+	}
+
+	return file, size, nil
+}
+
+func removeExportFile(file *os.File) {
+	file.Close()
+	os.Remove(file.Name())
+}
+
+// writeExport writes everything Chirpy holds about a user as a zip archive:
+// their profile, chirps with edit history, sessions, subscription history
+// and uploaded media.
+func (cfg *apiConfig) writeExport(ctx context.Context, userID uuid.UUID, w io.Writer) error {
+	type exportProfile struct {
+		User
+		TwoFactorEnabled bool `json:"two_factor_enabled"`
+	}
+
+	type exportChirp struct {
+		Chirp
+		PublishedAt *time.Time      `json:"published_at,omitempty"`
+		Revisions   []ChirpRevision `json:"revisions"`
+	}
+
+	type exportSession struct {
+		ID         uuid.UUID  `json:"id"`
+		CreatedAt  time.Time  `json:"created_at"`
+		LastUsedAt time.Time  `json:"last_used_at"`
+		ExpiresAt  time.Time  `json:"expires_at"`
+		RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+		UserAgent  string     `json:"user_agent"`
+		IPAddress  string     `json:"ip_address"`
+	}
+
+	type subscriptionEvent struct {
+		Event     string    `json:"event"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	user, err := cfg.db.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	archive := export.NewArchive(w, time.Now())
+
+	err = archive.AddJSON("profile.json", exportProfile{
+		User:             databaseUserToUser(user),
+		TwoFactorEnabled: user.TotpEnabledAt.Valid,
+	})
+	if err != nil {
+		return err
+	}
+
+	chirps, err := cfg.db.ExportUserChirps(ctx, userID)
+	if err != nil {
+		return err
+	}
+	revisions, err := cfg.db.ExportUserChirpRevisions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	revisionsByChirp := map[uuid.UUID][]ChirpRevision{}
+	for _, revision := range revisions {
+		revisionsByChirp[revision.ChirpID] = append(revisionsByChirp[revision.ChirpID], ChirpRevision{
+			ID:        revision.ID,
+			CreatedAt: revision.CreatedAt,
+			ChirpID:   revision.ChirpID,
+			Body:      revision.Body,
+		})
+	}
+
+	exportedChirps := make([]exportChirp, 0, len(chirps))
+	for _, chirp := range chirps {
+		exported := exportChirp{
+			Chirp:     databaseChirpToChirp(chirp),
+			Revisions: revisionsByChirp[chirp.ID],
+		}
+		if exported.Revisions == nil {
+			exported.Revisions = []ChirpRevision{}
+		}
+		if chirp.PublishedAt.Valid {
+			exported.PublishedAt = &chirp.PublishedAt.Time
+		}
+		exportedChirps = append(exportedChirps, exported)
+	}
+
+	withMedia := make([]*Chirp, 0, len(exportedChirps))
+	for i := range exportedChirps {
+		withMedia = append(withMedia, &exportedChirps[i].Chirp)
+	}
+	err = cfg.setChirpMedia(ctx, withMedia)
+	if err != nil {
+		return err
+	}
+
+	err = archive.AddJSON("chirps.json", exportedChirps)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := cfg.db.ExportUserSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	exportedSessions := make([]exportSession, 0, len(sessions))
+	for _, session := range sessions {
+		exported := exportSession{
+			ID:         session.FamilyID,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IpAddress,
+		}
+		if session.RevokedAt.Valid {
+			exported.RevokedAt = &session.RevokedAt.Time
+		}
+		exportedSessions = append(exportedSessions, exported)
+	}
+
+	err = archive.AddJSON("sessions.json", exportedSessions)
+	if err != nil {
+		return err
+	}
+
+	events, err := cfg.db.ExportSubscriptionEvents(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	exportedEvents := make([]subscriptionEvent, 0, len(events))
+	for _, event := range events {
+		exportedEvents = append(exportedEvents, subscriptionEvent{
+			Event:     event.Event,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+
+	err = archive.AddJSON("subscription.json", exportedEvents)
+	if err != nil {
+		return err
+	}
+
+	files, err := cfg.db.ExportUserMediaFiles(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	exportedFiles := make([]ChirpMedia, 0, len(files))
+	for _, file := range files {
+		exportedFiles = append(exportedFiles, databaseMediaToMedia(file))
+	}
+
+	err = archive.AddJSON("media.json", exportedFiles)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		err = cfg.addExportMedia(ctx, archive, file)
+		if err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+func (cfg *apiConfig) addExportMedia(ctx context.Context, archive *export.Archive, file database.MediaFile) error {
+	body, _, err := cfg.media.Get(ctx, file.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		log.Printf("Media %s is missing from storage, leaving it out of the export", file.StorageKey)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	return archive.AddFile("media/"+file.StorageKey, body, file.CreatedAt)
+}
+
+// runExports builds queued exports. Claiming an export marks it running, and
+// one left running for an hour by an instance that died is picked up again.
+func (cfg *apiConfig) runExports(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			dataExport, err := cfg.db.ClaimDataExport(context.Background())
+			if errors.Is(err, sql.ErrNoRows) {
+				break
+			}
+			if err != nil {
+				log.Printf("Unable to claim data export: %v", err)
+				break
+			}
+
+			cfg.buildExport(context.Background(), dataExport)
+		}
+
+		cfg.deleteExpiredExports(context.Background())
+
+		<-ticker.C
+	}
+}
+
+func (cfg *apiConfig) buildExport(ctx context.Context, dataExport database.DataExport) {
+	key := "export-" + dataExport.ID.String() + ".zip"
+
+	file, size, err := cfg.writeExportFile(ctx, dataExport.UserID)
+	if err == nil {
+		err = cfg.exports.Put(ctx, key, file, "application/zip")
+		removeExportFile(file)
+	}
+	if err != nil {
+		log.Printf("Unable to build data export %s: %v", dataExport.ID, err)
+
+		err = cfg.db.FailDataExport(ctx, database.FailDataExportParams{
+			ID:    dataExport.ID,
+			Error: sql.NullString{String: err.Error(), Valid: true},
+		})
+		if err != nil {
+			log.Printf("Unable to mark data export %s failed: %v", dataExport.ID, err)
+		}
+		return
+	}
+
+	err = cfg.db.CompleteDataExport(ctx, database.CompleteDataExportParams{
+		ID:         dataExport.ID,
+		StorageKey: sql.NullString{String: key, Valid: true},
+		SizeBytes:  size,
+		ExpiresAt:  sql.NullTime{Time: time.Now().UTC().Add(exportLifetime), Valid: true},
+	})
+	if err != nil {
+		log.Printf("Unable to mark data export %s ready: %v", dataExport.ID, err)
+	}
+}
+
+func (cfg *apiConfig) deleteExpiredExports(ctx context.Context) {
+	keys, err := cfg.db.DeleteExpiredDataExports(ctx)
+	if err != nil {
+		log.Printf("Unable to delete expired data exports: %v", err)
+		return
+	}
+
+	cfg.deleteStoredExports(ctx, keys)
+}
+
+func (cfg *apiConfig) deleteStoredExports(ctx context.Context, keys []sql.NullString) {
+	for _, key := range keys {
+		if !key.Valid {
+			continue
+		}
+
+		err := cfg.exports.Delete(ctx, key.String)
+		if err != nil {
+			log.Printf("Unable to delete data export %s: %v", key.String, err)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 )
@@ -22,17 +21,20 @@ func (cfg *apiConfig) handlerNumOfRequests(w http.ResponseWriter, req *http.Requ
 </html>`, cfg.fileserverHits.Load())))
 }
 
+// handlerResetHits wipes every user. It's only routed when PLATFORM is dev,
+// and checks again here in case that ever changes.
 func (cfg *apiConfig) handlerResetHits(w http.ResponseWriter, req *http.Request) {
 	if cfg.platform != "dev" {
-		w.WriteHeader(http.StatusForbidden)
-		w.Write([]byte("Forbidden Platform"))
+		respondWithError(w, http.StatusForbidden, "Forbidden Platform", nil)
+		return
 	}
-	cfg.fileserverHits.Store(0)
-	err := cfg.db.ResetUsers(context.Background())
+
+	err := cfg.db.ResetUsers(req.Context())
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Something went wrong", err)
 		return
 	}
+	cfg.fileserverHits.Store(0)
 
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("Hits reset to 0 and users have been reset."))
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+)
+
+type ChirpRevision struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ChirpID   uuid.UUID `json:"chirp_id"`
+	Body      string    `json:"body"`
+}
+
+func (cfg *apiConfig) handlerEditChirp(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Body string `json:"body"`
+	}
+
+	path := req.PathValue("chirpID")
+	chirpID, err := uuid.Parse(path)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	user, err := cfg.authorize(req.Context(), accessToken, auth.ScopeChirpsWrite)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode Chirp parameters", err)
+		return
+	}
+
+	verdict := cfg.moderator.Moderate(params.Body)
+	if verdict.Rejected {
+		respondWithError(w, http.StatusBadRequest, verdict.Reason(), nil)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to edit Chirp", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// Lock the row so concurrent edits can't both archive the same body.
+	chirp, err := qtx.LockChirp(req.Context(), chirpID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && (chirp.DeletedAt.Valid || !chirp.PublishedAt.Valid)) {
+		respondWithError(w, http.StatusNotFound, "Can't retrieve Chirp", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to edit Chirp", err)
+		return
+	}
+
+	if chirp.UserID != user {
+		respondWithError(w, http.StatusForbidden, "Incorrect user for Chirp", nil)
+		return
+	}
+
+	err = qtx.CreateChirpRevision(req.Context(), chirp.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to save Chirp history", err)
+		return
+	}
+
+	chirp, err = qtx.UpdateChirp(req.Context(), database.UpdateChirpParams{
+		ID:   chirp.ID,
+		Body: verdict.Body,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to edit Chirp", err)
+		return
+	}
+
+	err = saveChirpEntities(req.Context(), qtx, chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to edit Chirp", err)
+		return
+	}
+
+	if verdict.Flagged {
+		err = qtx.CreateModerationFlag(req.Context(), database.CreateModerationFlagParams{
+			ChirpID: chirp.ID,
+			Reason:  verdict.Reason(),
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to edit Chirp", err)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to edit Chirp", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, databaseChirpToChirp(chirp))
+}
+
+func (cfg *apiConfig) handlerChirpHistory(w http.ResponseWriter, req *http.Request) {
+	path := req.PathValue("chirpID")
+	chirpID, err := uuid.Parse(path)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	chirp, err := cfg.db.RetrieveChirp(req.Context(), chirpID)
+	if err != nil || !chirp.PublishedAt.Valid {
+		respondWithError(w, http.StatusNotFound, "Can't retrieve Chirp", err)
+		return
+	}
+
+	hidden, err := cfg.chirpHiddenFromViewer(req.Context(), cfg.viewerID(req), chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve Chirp history", err)
+		return
+	}
+	if hidden {
+		respondWithError(w, http.StatusNotFound, "Can't retrieve Chirp", nil)
+		return
+	}
+
+	revisions, err := cfg.db.GetChirpRevisions(req.Context(), chirp.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve Chirp history", err)
+		return
+	}
+
+	convertedRevisions := []ChirpRevision{}
+	for _, revision := range revisions {
+		convertedRevisions = append(convertedRevisions, ChirpRevision{
+			ID:        revision.ID,
+			CreatedAt: revision.CreatedAt,
+			ChirpID:   revision.ChirpID,
+			Body:      revision.Body,
+		})
+	}
+
+	respondWithJson(w, http.StatusOK, convertedRevisions)
+}
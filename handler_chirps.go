@@ -1,29 +1,36 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
-	"sort"
-	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/willthefoollearn/chirpy/internal/auth"
 	"github.com/willthefoollearn/chirpy/internal/database"
+	"github.com/willthefoollearn/chirpy/internal/pagination"
 )
 
 func (cfg *apiConfig) handlerChirps(w http.ResponseWriter, req *http.Request) {
 	type parameters struct {
-		Body string `json:"body"`
+		Body      string      `json:"body"`
+		InReplyTo *uuid.UUID  `json:"in_reply_to"`
+		MediaIDs  []uuid.UUID `json:"media_ids"`
+		Draft     bool        `json:"draft"`
+		PublishAt *time.Time  `json:"publish_at"`
 	}
 
 	token, err := auth.GetBearerToken(req.Header)
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "Unable to retrieve token", err)
+		return
 	}
 
-	user, err := auth.ValidateJWT(token, cfg.secret)
+	user, err := cfg.authorize(req.Context(), token, auth.ScopeChirpsWrite)
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Token doesn't match user", err)
+		respondWithAuthError(w, "Token doesn't match user", err)
+		return
 	}
 
 	decoder := json.NewDecoder(req.Body)
@@ -35,108 +42,286 @@ func (cfg *apiConfig) handlerChirps(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	if len(params.Body) > 140 {
-		respondWithError(w, http.StatusBadRequest, "Chirp is too long", nil)
+	verdict := cfg.moderator.Moderate(params.Body)
+	if verdict.Rejected {
+		respondWithError(w, http.StatusBadRequest, verdict.Reason(), nil)
 		return
 	}
 
-	splitBody := strings.Split(params.Body, " ")
-	for i, word := range splitBody {
-		low := strings.ToLower(word)
-		if low == "kerfuffle" || low == "sharbert" || low == "fornax" {
-			splitBody[i] = "****"
-		}
+	if len(params.MediaIDs) > maxMediaPerChirp {
+		respondWithError(w, http.StatusBadRequest, "A Chirp can have at most 4 attachments", nil)
+		return
+	}
+
+	publishAt, publishNow, err := parsePublishTime(params.Draft, params.PublishAt)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid publish time", err)
+		return
+	}
+	if !publishNow && params.InReplyTo != nil {
+		respondWithError(w, http.StatusBadRequest, "Replies can't be drafted or scheduled", nil)
+		return
 	}
-	cleanedBody := strings.Join(splitBody, " ")
+
 	var args = database.CreateChirpParams{
-		Body:   cleanedBody,
+		Body:   verdict.Body,
 		UserID: user,
 	}
 
-	chirp, err := cfg.db.CreateChirp(req.Context(), args)
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Unable to create Chirp", err)
 		return
 	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
 
-	respondWithJson(w, http.StatusCreated, Chirp{
-		ID:        chirp.ID,
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.CreatedAt,
-		Body:      chirp.Body,
-		UserID:    chirp.UserID,
-	})
+	if params.InReplyTo != nil {
+		// Bumping the counter first also locks the parent, so it can't be
+		// deleted out from under the reply.
+		updated, err := qtx.IncrementReplyCount(req.Context(), *params.InReplyTo)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to create Chirp", err)
+			return
+		}
+		if updated == 0 {
+			respondWithError(w, http.StatusNotFound, "Can't find Chirp to reply to", nil)
+			return
+		}
+
+		parent, err := qtx.RetrieveChirp(req.Context(), *params.InReplyTo)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to create Chirp", err)
+			return
+		}
+		if !checkNotBlocked(w, req, qtx, user, parent.UserID) {
+			return
+		}
+		args.ParentID = uuid.NullUUID{UUID: *params.InReplyTo, Valid: true}
+	}
+
+	var chirp database.Chirp
+	if publishNow {
+		chirp, err = qtx.CreateChirp(req.Context(), args)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to create Chirp", err)
+			return
+		}
+
+		err = saveChirpEntities(req.Context(), qtx, chirp)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to create Chirp", err)
+			return
+		}
+	} else {
+		// Hashtags and mentions are only indexed once the chirp goes out.
+		chirp, err = qtx.CreateUnpublishedChirp(req.Context(), database.CreateUnpublishedChirpParams{
+			Body:      args.Body,
+			UserID:    args.UserID,
+			PublishAt: publishAt,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to create Chirp", err)
+			return
+		}
+	}
+
+	attached, status, err := attachChirpMedia(req.Context(), qtx, user, chirp.ID, params.MediaIDs)
+	if err != nil {
+		respondWithError(w, status, "Unable to attach media to Chirp", err)
+		return
+	}
+
+	if verdict.Flagged {
+		err = qtx.CreateModerationFlag(req.Context(), database.CreateModerationFlagParams{
+			ChirpID: chirp.ID,
+			Reason:  verdict.Reason(),
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to create Chirp", err)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to create Chirp", err)
+		return
+	}
+
+	convertedChirp := databaseChirpToChirp(chirp)
+	convertedChirp.Media = attached
+
+	respondWithJson(w, http.StatusCreated, convertedChirp)
 }
 
 func (cfg *apiConfig) handlerListChirps(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Query().Get("q") != "" {
+		cfg.handlerSearchChirps(w, req)
+		return
+	}
+
 	author_id := req.URL.Query().Get("author_id")
 	sortMethod := req.URL.Query().Get("sort")
 	if sortMethod != "desc" {
 		sortMethod = "asc"
 	}
 
-	var chirps []database.Chirp
-	var err error
+	page, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid pagination parameters", err)
+		return
+	}
+
+	viewer := cfg.viewerID(req)
+	convertedChirps := []Chirp{}
+	cursors := []pagination.Cursor{}
 
 	if author_id == "" {
-		chirps, err = cfg.db.GetChirps(req.Context())
+		chirps, err := cfg.listChirps(req.Context(), viewer, sortMethod, page)
 		if err != nil {
-			respondWithError(w, http.StatusInternalServerError, "Can't retrieve Chirps", nil)
+			respondWithError(w, http.StatusInternalServerError, "Can't retrieve Chirps", err)
+			return
+		}
+
+		for _, chirp := range chirps {
+			convertedChirps = append(convertedChirps, databaseChirpToChirp(chirp))
+			cursors = append(cursors, pagination.Cursor{
+				CreatedAt: chirp.CreatedAt,
+				ID:        chirp.ID,
+			})
 		}
 	} else {
-		user, err := uuid.Parse(author_id)
+		author, err := uuid.Parse(author_id)
 		if err != nil {
-			respondWithError(w, http.StatusInternalServerError, "Can't retrieve user", nil)
+			respondWithError(w, http.StatusBadRequest, "Can't parse author_id", err)
+			return
 		}
 
-		chirps, err = cfg.db.GetChirpsByUser(req.Context(), user)
+		rows, err := cfg.listAuthorChirps(req.Context(), viewer, author, sortMethod, page)
 		if err != nil {
-			respondWithError(w, http.StatusInternalServerError, "Can't retrieve Chirps", nil)
+			respondWithError(w, http.StatusInternalServerError, "Can't retrieve Chirps", err)
+			return
+		}
+
+		// An author's list holds their own chirps and the ones they reposted,
+		// ordered by when each showed up on their profile.
+		for _, row := range rows {
+			chirp := databaseChirpToChirp(row.Chirp)
+			if row.RepostedBy.Valid {
+				chirp.RepostedBy = &row.RepostedBy.UUID
+				chirp.RepostedAt = &row.ActivityAt
+			}
+			convertedChirps = append(convertedChirps, chirp)
+			cursors = append(cursors, pagination.Cursor{
+				CreatedAt: row.ActivityAt,
+				ID:        row.Chirp.ID,
+			})
 		}
 	}
 
-	convertedChirps := []Chirp{}
+	if len(convertedChirps) > int(page.Limit) {
+		convertedChirps = convertedChirps[:page.Limit]
+		setNextPageLink(w, req, cursors[page.Limit-1])
+	}
 
-	for _, chirp := range chirps {
-		convertedChirps = append(convertedChirps, Chirp{
-			ID:        chirp.ID,
-			CreatedAt: chirp.CreatedAt,
-			UpdatedAt: chirp.CreatedAt,
-			Body:      chirp.Body,
-			UserID:    chirp.UserID,
-		})
+	viewerChirps := make([]*Chirp, 0, len(convertedChirps))
+	for i := range convertedChirps {
+		viewerChirps = append(viewerChirps, &convertedChirps[i])
 	}
+	err = cfg.decorateChirps(req.Context(), viewer, viewerChirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve Chirps", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, convertedChirps)
+}
 
+// listChirps fetches one page of every chirp the viewer may see, plus one
+// extra row so the caller can tell whether another page follows.
+func (cfg *apiConfig) listChirps(ctx context.Context, viewer uuid.NullUUID, sortMethod string, page pageParams) ([]database.Chirp, error) {
 	if sortMethod == "desc" {
-		sort.Slice(convertedChirps, func(i, j int) bool {
-			comparison := convertedChirps[i].CreatedAt.Compare(convertedChirps[j].CreatedAt)
+		return cfg.db.ListChirpsDesc(ctx, database.ListChirpsDescParams{
+			ViewerID:       viewer,
+			AfterCreatedAt: page.AfterCreatedAt,
+			AfterID:        page.AfterID,
+			RowLimit:       page.Limit + 1,
+		})
+	}
+
+	return cfg.db.ListChirpsAsc(ctx, database.ListChirpsAscParams{
+		ViewerID:       viewer,
+		AfterCreatedAt: page.AfterCreatedAt,
+		AfterID:        page.AfterID,
+		RowLimit:       page.Limit + 1,
+	})
+}
 
-			return comparison == 1
+// listAuthorChirps is listChirps for a single author's profile, reposts
+// included.
+func (cfg *apiConfig) listAuthorChirps(ctx context.Context, viewer uuid.NullUUID, author uuid.UUID, sortMethod string, page pageParams) ([]database.ListAuthorChirpsAscRow, error) {
+	if sortMethod == "desc" {
+		descRows, err := cfg.db.ListAuthorChirpsDesc(ctx, database.ListAuthorChirpsDescParams{
+			AuthorID:       author,
+			ViewerID:       viewer,
+			AfterCreatedAt: page.AfterCreatedAt,
+			AfterID:        page.AfterID,
+			RowLimit:       page.Limit + 1,
 		})
+		if err != nil {
+			return nil, err
+		}
+
+		rows := make([]database.ListAuthorChirpsAscRow, 0, len(descRows))
+		for _, row := range descRows {
+			rows = append(rows, database.ListAuthorChirpsAscRow(row))
+		}
+		return rows, nil
 	}
 
-	respondWithJson(w, http.StatusOK, convertedChirps)
+	return cfg.db.ListAuthorChirpsAsc(ctx, database.ListAuthorChirpsAscParams{
+		AuthorID:       author,
+		ViewerID:       viewer,
+		AfterCreatedAt: page.AfterCreatedAt,
+		AfterID:        page.AfterID,
+		RowLimit:       page.Limit + 1,
+	})
 }
 
 func (cfg *apiConfig) handlerRetrieveChirp(w http.ResponseWriter, req *http.Request) {
 	path := req.PathValue("chirpID")
 	parsedUuid, err := uuid.Parse(path)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Can't parse path", nil)
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
 	}
 
 	chirp, err := cfg.db.RetrieveChirp(req.Context(), parsedUuid)
+	if err != nil || !chirp.PublishedAt.Valid {
+		respondWithError(w, http.StatusNotFound, "Can't retrieve Chirp", err)
+		return
+	}
+
+	viewer := cfg.viewerID(req)
+	hidden, err := cfg.chirpHiddenFromViewer(req.Context(), viewer, chirp)
 	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve Chirp", err)
+		return
+	}
+	if hidden {
 		respondWithError(w, http.StatusNotFound, "Can't retrieve Chirp", nil)
+		return
 	}
 
-	respondWithJson(w, http.StatusOK, Chirp{
-		ID:        chirp.ID,
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.CreatedAt,
-		Body:      chirp.Body,
-		UserID:    chirp.UserID,
-	})
+	convertedChirp := databaseChirpToChirp(chirp)
+	err = cfg.decorateChirps(req.Context(), viewer, []*Chirp{&convertedChirp})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve Chirp", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, convertedChirp)
 }
 
 func (cfg *apiConfig) handlerDeleteChirp(w http.ResponseWriter, req *http.Request) {
@@ -144,35 +329,88 @@ func (cfg *apiConfig) handlerDeleteChirp(w http.ResponseWriter, req *http.Reques
 	parsedUuid, err := uuid.Parse(path)
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
 	}
 
-	chirp, err := cfg.db.RetrieveChirp(req.Context(), parsedUuid)
+	accessToken, err := auth.GetBearerToken(req.Header)
 	if err != nil {
-		respondWithError(w, http.StatusNotFound, "Can't retrieve Chirp", err)
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
 	}
 
-	accessToken, err := auth.GetBearerToken(req.Header)
+	user, err := cfg.authorize(req.Context(), accessToken, auth.ScopeChirpsDelete)
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		respondWithAuthError(w, "User can't be found with accessToken", err)
 		return
 	}
 
-	user, err := auth.ValidateJWT(accessToken, cfg.secret)
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "User can't be found with accessToken", err)
+		respondWithError(w, http.StatusInternalServerError, "Unable to delete Chirp", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	chirp, err := qtx.LockChirp(req.Context(), parsedUuid)
+	if err != nil || chirp.DeletedAt.Valid {
+		respondWithError(w, http.StatusNotFound, "Can't retrieve Chirp", err)
 		return
 	}
 
-	if chirp.UserID == user {
-		err = cfg.db.DeleteChirp(req.Context(), chirp.ID)
+	if chirp.UserID != user {
+		respondWithError(w, http.StatusForbidden, "Incorrect user for Chirp", nil)
+		return
+	}
+
+	mediaKeys, err := detachChirpMedia(req.Context(), qtx, chirp.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to delete Chirp", err)
+		return
+	}
+
+	// A chirp with replies is replaced by a tombstone so the conversation
+	// below it keeps its shape. Leaf chirps are removed outright.
+	if chirp.ReplyCount > 0 {
+		tombstone, err := qtx.TombstoneChirp(req.Context(), chirp.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to delete Chirp", err)
+			return
+		}
+
+		err = saveChirpEntities(req.Context(), qtx, tombstone)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to delete Chirp", err)
+			return
+		}
+
+		err = qtx.DeleteChirpRevisions(req.Context(), chirp.ID)
 		if err != nil {
-			respondWithError(w, http.StatusUnauthorized, "No chirp to delete, ID didn't match", err)
+			respondWithError(w, http.StatusInternalServerError, "Unable to delete Chirp", err)
 			return
 		}
 	} else {
-		respondWithError(w, http.StatusForbidden, "Incorrect user for Chirp", err)
+		err = qtx.DeleteChirp(req.Context(), chirp.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to delete Chirp", err)
+			return
+		}
+
+		if chirp.ParentID.Valid {
+			err = qtx.DecrementReplyCount(req.Context(), chirp.ParentID.UUID)
+			if err != nil {
+				respondWithError(w, http.StatusInternalServerError, "Unable to delete Chirp", err)
+				return
+			}
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to delete Chirp", err)
 		return
 	}
+	cfg.deleteStoredMedia(req.Context(), mediaKeys)
 
 	w.WriteHeader(http.StatusNoContent)
 }
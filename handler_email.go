@@ -0,0 +1,251 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+)
+
+// handlerRequestPasswordReset always answers the same way, whether or not the
+// address has an account, so it can't be used to find out. Requests are
+// throttled per address and per client, the way logins are, so it can't be
+// used to flood an inbox either.
+func (cfg *apiConfig) handlerRequestPasswordReset(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Email string `json:"email"`
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parameters couldn't be decoded", err)
+		return
+	}
+
+	throttle := newPasswordResetThrottle(req, params.Email)
+	lockedUntil, err := throttle.begin(req.Context(), cfg.conn, cfg.db)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to start password reset", err)
+		return
+	}
+	if !lockedUntil.IsZero() {
+		respondWithThrottled(w, lockedUntil, "Too many password reset requests, try again later")
+		return
+	}
+
+	user, err := cfg.db.LookUpUser(req.Context(), params.Email)
+	if err == nil {
+		err = cfg.sendPasswordResetEmail(req.Context(), user)
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusInternalServerError, "Unable to start password reset", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handlerConfirmPasswordReset sets a new password from a reset link. Every
+// existing session is ended, since whoever had the old password may still be
+// signed in.
+func (cfg *apiConfig) handlerConfirmPasswordReset(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parameters couldn't be decoded", err)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to reset password", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	token, ok := lockEmailToken(w, req, qtx, params.Token, purposePasswordReset)
+	if !ok {
+		return
+	}
+
+	// The token stays unused if the password is rejected, since nothing
+	// here is committed.
+	err = auth.CheckPasswordStrength(params.Password, token.Email)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	hashedPassword, err := auth.HashPassword(params.Password)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Password couldn't be hashed", err)
+		return
+	}
+
+	_, err = qtx.UpdatePassword(req.Context(), database.UpdatePasswordParams{
+		ID:             token.UserID,
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to reset password", err)
+		return
+	}
+
+	err = qtx.ExpireEmailTokens(req.Context(), database.ExpireEmailTokensParams{
+		UserID:  token.UserID,
+		Purpose: purposePasswordReset,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to reset password", err)
+		return
+	}
+
+	err = qtx.RevokeAllUserSessions(req.Context(), token.UserID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to reset password", err)
+		return
+	}
+
+	_, err = qtx.IncrementTokenVersion(req.Context(), token.UserID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to reset password", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to reset password", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlerConfirmEmail marks an address as verified, switching the account
+// over to it if it was a change of address.
+func (cfg *apiConfig) handlerConfirmEmail(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Token string `json:"token"`
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parameters couldn't be decoded", err)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to verify email", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	token, ok := lockEmailToken(w, req, qtx, params.Token, purposeVerifyEmail)
+	if !ok {
+		return
+	}
+
+	user, err := qtx.VerifyEmail(req.Context(), database.VerifyEmailParams{
+		ID:    token.UserID,
+		Email: token.Email,
+	})
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "Email is already in use", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to verify email", err)
+		return
+	}
+
+	err = qtx.ExpireEmailTokens(req.Context(), database.ExpireEmailTokensParams{
+		UserID:  token.UserID,
+		Purpose: purposeVerifyEmail,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to verify email", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to verify email", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, databaseUserToUser(user))
+}
+
+func (cfg *apiConfig) handlerResendVerification(w http.ResponseWriter, req *http.Request) {
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	userID, err := cfg.authorize(req.Context(), accessToken, auth.ScopeProfileWrite)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	user, err := cfg.db.GetUser(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to send verification email", err)
+		return
+	}
+	if user.EmailVerifiedAt.Valid {
+		respondWithError(w, http.StatusConflict, "Email is already verified", nil)
+		return
+	}
+
+	err = cfg.sendVerificationEmail(req.Context(), user.ID, user.Email)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to send verification email", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// lockEmailToken finds and locks an unused, unexpired token for purpose and
+// marks it used. It writes the error response itself when there isn't one.
+func lockEmailToken(w http.ResponseWriter, req *http.Request, db *database.Queries, rawToken, purpose string) (database.EmailToken, bool) {
+	token, err := db.LockEmailToken(req.Context(), database.LockEmailTokenParams{
+		TokenHash: auth.HashToken(rawToken),
+		Purpose:   purpose,
+	})
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && (token.UsedAt.Valid || !token.ExpiresAt.After(time.Now()))) {
+		respondWithError(w, http.StatusBadRequest, "Link is invalid or has expired", err)
+		return database.EmailToken{}, false
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to check link", err)
+		return database.EmailToken{}, false
+	}
+
+	err = db.UseEmailToken(req.Context(), token.TokenHash)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to check link", err)
+		return database.EmailToken{}, false
+	}
+
+	return token, true
+}
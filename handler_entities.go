@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/willthefoollearn/chirpy/internal/database"
+	"github.com/willthefoollearn/chirpy/internal/entities"
+	"github.com/willthefoollearn/chirpy/internal/pagination"
+)
+
+type ChirpEntity struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+func chirpEntities(body string) []ChirpEntity {
+	converted := []ChirpEntity{}
+	for _, entity := range entities.Extract(body) {
+		converted = append(converted, ChirpEntity{
+			Type:  string(entity.Kind),
+			Text:  entity.Text,
+			Start: entity.Start,
+			End:   entity.End,
+		})
+	}
+
+	return converted
+}
+
+// saveChirpEntities replaces the stored hashtags and mentions of a chirp with
+// the ones in its current body.
+func saveChirpEntities(ctx context.Context, db *database.Queries, chirp database.Chirp) error {
+	err := db.DeleteChirpHashtags(ctx, chirp.ID)
+	if err != nil {
+		return err
+	}
+
+	err = db.DeleteChirpMentions(ctx, chirp.ID)
+	if err != nil {
+		return err
+	}
+
+	found := entities.Extract(chirp.Body)
+
+	tags := entities.Names(found, entities.Hashtag)
+	if len(tags) > 0 {
+		err = db.AddChirpHashtags(ctx, database.AddChirpHashtagsParams{
+			ChirpID:   chirp.ID,
+			Tags:      tags,
+			CreatedAt: chirp.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	handles := entities.Names(found, entities.Mention)
+	if len(handles) > 0 {
+		err = db.AddChirpMentions(ctx, database.AddChirpMentionsParams{
+			ChirpID:   chirp.ID,
+			Handles:   handles,
+			CreatedAt: chirp.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cfg *apiConfig) handlerHashtagChirps(w http.ResponseWriter, req *http.Request) {
+	tag := strings.ToLower(strings.TrimPrefix(req.PathValue("tag"), "#"))
+	if tag == "" {
+		respondWithError(w, http.StatusBadRequest, "Missing hashtag", nil)
+		return
+	}
+
+	page, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid pagination parameters", err)
+		return
+	}
+
+	viewer := cfg.viewerID(req)
+	rows, err := cfg.db.ListHashtagChirps(req.Context(), database.ListHashtagChirpsParams{
+		Tag:            tag,
+		ViewerID:       viewer,
+		AfterCreatedAt: page.AfterCreatedAt,
+		AfterID:        page.AfterID,
+		RowLimit:       page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve Chirps", err)
+		return
+	}
+
+	if len(rows) > int(page.Limit) {
+		rows = rows[:page.Limit]
+		last := rows[len(rows)-1]
+		setNextPageLink(w, req, pagination.Cursor{
+			CreatedAt: last.TaggedAt,
+			ID:        last.Chirp.ID,
+		})
+	}
+
+	convertedChirps := []Chirp{}
+	for _, row := range rows {
+		convertedChirps = append(convertedChirps, databaseChirpToChirp(row.Chirp))
+	}
+
+	viewerChirps := make([]*Chirp, 0, len(convertedChirps))
+	for i := range convertedChirps {
+		viewerChirps = append(viewerChirps, &convertedChirps[i])
+	}
+	err = cfg.decorateChirps(req.Context(), viewer, viewerChirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve Chirps", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, convertedChirps)
+}
+
+func (cfg *apiConfig) handlerUserMentions(w http.ResponseWriter, req *http.Request) {
+	user, err := cfg.resolveUser(req.Context(), req.PathValue("userID"))
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "User can't be found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve mentions", err)
+		return
+	}
+
+	page, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid pagination parameters", err)
+		return
+	}
+
+	viewer := cfg.viewerID(req)
+	rows, err := cfg.db.ListUserMentions(req.Context(), database.ListUserMentionsParams{
+		UserID:         user,
+		ViewerID:       viewer,
+		AfterCreatedAt: page.AfterCreatedAt,
+		AfterID:        page.AfterID,
+		RowLimit:       page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve mentions", err)
+		return
+	}
+
+	if len(rows) > int(page.Limit) {
+		rows = rows[:page.Limit]
+		last := rows[len(rows)-1]
+		setNextPageLink(w, req, pagination.Cursor{
+			CreatedAt: last.MentionedAt,
+			ID:        last.Chirp.ID,
+		})
+	}
+
+	convertedChirps := []Chirp{}
+	for _, row := range rows {
+		convertedChirps = append(convertedChirps, databaseChirpToChirp(row.Chirp))
+	}
+
+	viewerChirps := make([]*Chirp, 0, len(convertedChirps))
+	for i := range convertedChirps {
+		viewerChirps = append(viewerChirps, &convertedChirps[i])
+	}
+	err = cfg.decorateChirps(req.Context(), viewer, viewerChirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve mentions", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, convertedChirps)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+	"github.com/willthefoollearn/chirpy/internal/pagination"
+)
+
+type Follow struct {
+	UserID     uuid.UUID `json:"user_id"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+func (cfg *apiConfig) handlerFollow(w http.ResponseWriter, req *http.Request) {
+	followee, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	user, err := cfg.authorize(req.Context(), accessToken, auth.ScopeProfileWrite)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	if followee == user {
+		respondWithError(w, http.StatusBadRequest, "Users can't follow themselves", nil)
+		return
+	}
+
+	_, err = cfg.db.GetUser(req.Context(), followee)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Unable to find user", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to follow user", err)
+		return
+	}
+
+	if !checkNotBlocked(w, req, cfg.db, user, followee) {
+		return
+	}
+
+	err = cfg.db.FollowUser(req.Context(), database.FollowUserParams{
+		FollowerID: user,
+		FolloweeID: followee,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to follow user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerUnfollow(w http.ResponseWriter, req *http.Request) {
+	followee, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	user, err := cfg.authorize(req.Context(), accessToken, auth.ScopeProfileWrite)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	err = cfg.db.UnfollowUser(req.Context(), database.UnfollowUserParams{
+		FollowerID: user,
+		FolloweeID: followee,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to unfollow user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerListFollowers(w http.ResponseWriter, req *http.Request) {
+	user, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	page, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid pagination parameters", err)
+		return
+	}
+
+	followers, err := cfg.db.ListFollowers(req.Context(), database.ListFollowersParams{
+		UserID:         user,
+		AfterCreatedAt: page.AfterCreatedAt,
+		AfterID:        page.AfterID,
+		RowLimit:       page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve followers", err)
+		return
+	}
+
+	if len(followers) > int(page.Limit) {
+		followers = followers[:page.Limit]
+		last := followers[len(followers)-1]
+		setNextPageLink(w, req, pagination.Cursor{
+			CreatedAt: last.CreatedAt,
+			ID:        last.FollowerID,
+		})
+	}
+
+	convertedFollows := []Follow{}
+	for _, follower := range followers {
+		convertedFollows = append(convertedFollows, Follow{
+			UserID:     follower.FollowerID,
+			FollowedAt: follower.CreatedAt,
+		})
+	}
+
+	respondWithJson(w, http.StatusOK, convertedFollows)
+}
+
+func (cfg *apiConfig) handlerListFollowing(w http.ResponseWriter, req *http.Request) {
+	user, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	page, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid pagination parameters", err)
+		return
+	}
+
+	following, err := cfg.db.ListFollowing(req.Context(), database.ListFollowingParams{
+		UserID:         user,
+		AfterCreatedAt: page.AfterCreatedAt,
+		AfterID:        page.AfterID,
+		RowLimit:       page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve followed users", err)
+		return
+	}
+
+	if len(following) > int(page.Limit) {
+		following = following[:page.Limit]
+		last := following[len(following)-1]
+		setNextPageLink(w, req, pagination.Cursor{
+			CreatedAt: last.CreatedAt,
+			ID:        last.FolloweeID,
+		})
+	}
+
+	convertedFollows := []Follow{}
+	for _, followee := range following {
+		convertedFollows = append(convertedFollows, Follow{
+			UserID:     followee.FolloweeID,
+			FollowedAt: followee.CreatedAt,
+		})
+	}
+
+	respondWithJson(w, http.StatusOK, convertedFollows)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+)
+
+// reactionTarget authenticates the caller and checks that the chirp in the
+// path can still be liked or reposted. It writes the error response itself.
+func (cfg *apiConfig) reactionTarget(w http.ResponseWriter, req *http.Request) (uuid.UUID, database.Chirp, bool) {
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return uuid.Nil, database.Chirp{}, false
+	}
+
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return uuid.Nil, database.Chirp{}, false
+	}
+
+	user, err := cfg.authorize(req.Context(), accessToken, auth.ScopeChirpsWrite)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return uuid.Nil, database.Chirp{}, false
+	}
+
+	chirp, err := cfg.db.RetrieveChirp(req.Context(), chirpID)
+	if err != nil || chirp.DeletedAt.Valid || !chirp.PublishedAt.Valid || chirp.HiddenAt.Valid {
+		respondWithError(w, http.StatusNotFound, "Can't retrieve Chirp", err)
+		return uuid.Nil, database.Chirp{}, false
+	}
+
+	return user, chirp, true
+}
+
+func (cfg *apiConfig) handlerLikeChirp(w http.ResponseWriter, req *http.Request) {
+	user, chirp, ok := cfg.reactionTarget(w, req)
+	if !ok {
+		return
+	}
+
+	if !checkNotBlocked(w, req, cfg.db, user, chirp.UserID) {
+		return
+	}
+
+	err := cfg.db.LikeChirp(req.Context(), database.LikeChirpParams{
+		UserID:  user,
+		ChirpID: chirp.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to like Chirp", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerUnlikeChirp(w http.ResponseWriter, req *http.Request) {
+	user, chirp, ok := cfg.reactionTarget(w, req)
+	if !ok {
+		return
+	}
+
+	err := cfg.db.UnlikeChirp(req.Context(), database.UnlikeChirpParams{
+		UserID:  user,
+		ChirpID: chirp.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to unlike Chirp", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerRepostChirp(w http.ResponseWriter, req *http.Request) {
+	user, chirp, ok := cfg.reactionTarget(w, req)
+	if !ok {
+		return
+	}
+
+	if !checkNotBlocked(w, req, cfg.db, user, chirp.UserID) {
+		return
+	}
+
+	err := cfg.db.RepostChirp(req.Context(), database.RepostChirpParams{
+		UserID:  user,
+		ChirpID: chirp.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to repost Chirp", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerUnrepostChirp(w http.ResponseWriter, req *http.Request) {
+	user, chirp, ok := cfg.reactionTarget(w, req)
+	if !ok {
+		return
+	}
+
+	err := cfg.db.UnrepostChirp(req.Context(), database.UnrepostChirpParams{
+		UserID:  user,
+		ChirpID: chirp.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to remove repost", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
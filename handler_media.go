@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+	"github.com/willthefoollearn/chirpy/internal/media"
+)
+
+func (cfg *apiConfig) handlerUploadMedia(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Unable to retrieve token", err)
+		return
+	}
+
+	user, err := cfg.authorize(req.Context(), token, auth.ScopeChirpsWrite)
+	if err != nil {
+		respondWithAuthError(w, "Token doesn't match user", err)
+		return
+	}
+
+	// Leave some room for the multipart framing around the file itself.
+	req.Body = http.MaxBytesReader(w, req.Body, maxUploadBytes+64<<10)
+
+	file, _, err := req.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "Upload is too large", err)
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "Unable to read upload", err)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxUploadBytes+1))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to read upload", err)
+		return
+	}
+	if len(data) > maxUploadBytes {
+		respondWithError(w, http.StatusRequestEntityTooLarge, "Upload is too large", nil)
+		return
+	}
+
+	processed, err := media.Process(data)
+	if errors.Is(err, media.ErrUnsupportedType) {
+		respondWithError(w, http.StatusUnsupportedMediaType, "Only JPEG, PNG and GIF images are supported", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to read image", err)
+		return
+	}
+
+	key := uuid.New().String() + processed.Extension
+	err = cfg.media.Put(req.Context(), key, bytes.NewReader(processed.Data), processed.ContentType)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to store media", err)
+		return
+	}
+
+	record, err := cfg.db.CreateMediaFile(req.Context(), database.CreateMediaFileParams{
+		UserID:      user,
+		StorageKey:  key,
+		ContentType: processed.ContentType,
+		SizeBytes:   int64(len(processed.Data)),
+		Width:       int32(processed.Width),
+		Height:      int32(processed.Height),
+	})
+	if err != nil {
+		cfg.media.Delete(req.Context(), key)
+		respondWithError(w, http.StatusInternalServerError, "Unable to store media", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusCreated, databaseMediaToMedia(record))
+}
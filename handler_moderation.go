@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ModerationFlag struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ChirpID   uuid.UUID `json:"chirp_id"`
+	Reason    string    `json:"reason"`
+}
+
+func (cfg *apiConfig) handlerListModerationWords(w http.ResponseWriter, req *http.Request) {
+	words, err := cfg.db.ListModerationWords(req.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve moderation words", err)
+		return
+	}
+
+	if words == nil {
+		words = []string{}
+	}
+
+	respondWithJson(w, http.StatusOK, words)
+}
+
+func (cfg *apiConfig) handlerAddModerationWord(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Word string `json:"word"`
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode body", err)
+		return
+	}
+
+	word := strings.ToLower(strings.TrimSpace(params.Word))
+	if word == "" || strings.ContainsAny(word, " \t\n") {
+		respondWithError(w, http.StatusBadRequest, "Word must be a single word", nil)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to add moderation word", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	err = qtx.AddModerationWord(req.Context(), word)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to add moderation word", err)
+		return
+	}
+
+	err = recordAudit(req.Context(), qtx, adminFromContext(req.Context()), "moderation_word.add", "moderation_word", word, "")
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to add moderation word", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to add moderation word", err)
+		return
+	}
+
+	err = cfg.reloadModerationWords(req.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to reload moderation words", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerDeleteModerationWord(w http.ResponseWriter, req *http.Request) {
+	word := strings.ToLower(req.PathValue("word"))
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to delete moderation word", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	deleted, err := qtx.DeleteModerationWord(req.Context(), word)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to delete moderation word", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Unable to find moderation word", nil)
+		return
+	}
+
+	err = recordAudit(req.Context(), qtx, adminFromContext(req.Context()), "moderation_word.delete", "moderation_word", word, "")
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to delete moderation word", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to delete moderation word", err)
+		return
+	}
+
+	err = cfg.reloadModerationWords(req.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to reload moderation words", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerListModerationFlags(w http.ResponseWriter, req *http.Request) {
+	flags, err := cfg.db.ListOpenModerationFlags(req.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve moderation flags", err)
+		return
+	}
+
+	convertedFlags := []ModerationFlag{}
+	for _, flag := range flags {
+		convertedFlags = append(convertedFlags, ModerationFlag{
+			ID:        flag.ID,
+			CreatedAt: flag.CreatedAt,
+			ChirpID:   flag.ChirpID,
+			Reason:    flag.Reason,
+		})
+	}
+
+	respondWithJson(w, http.StatusOK, convertedFlags)
+}
+
+func (cfg *apiConfig) handlerResolveModerationFlag(w http.ResponseWriter, req *http.Request) {
+	flagID, err := uuid.Parse(req.PathValue("flagID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to resolve moderation flag", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	resolved, err := qtx.ResolveModerationFlag(req.Context(), flagID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to resolve moderation flag", err)
+		return
+	}
+	if resolved == 0 {
+		respondWithError(w, http.StatusNotFound, "Unable to find open moderation flag", nil)
+		return
+	}
+
+	err = recordAudit(req.Context(), qtx, adminFromContext(req.Context()), "moderation_flag.resolve", "moderation_flag", flagID.String(), "")
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to resolve moderation flag", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to resolve moderation flag", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
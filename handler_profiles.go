@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/willthefoollearn/chirpy/internal/database"
+	"github.com/willthefoollearn/chirpy/internal/entities"
+)
+
+const (
+	maxDisplayNameLength = 50
+	maxBioLength         = 160
+)
+
+var (
+	errDisplayNameTooLong = errors.New("display name must be at most 50 characters")
+	errBioTooLong         = errors.New("bio must be at most 160 characters")
+	errInvalidAvatar      = errors.New("avatar must be an image you uploaded")
+)
+
+// Author is the part of a profile embedded in each chirp, so clients can
+// show who wrote it without looking every author up.
+type Author struct {
+	ID          uuid.UUID `json:"id"`
+	Handle      string    `json:"handle,omitempty"`
+	DisplayName string    `json:"display_name"`
+	AvatarURL   string    `json:"avatar_url,omitempty"`
+}
+
+// Profile is what anyone can see about a user. It never includes the email.
+type Profile struct {
+	Author
+	Bio            string    `json:"bio"`
+	CreatedAt      time.Time `json:"created_at"`
+	FollowerCount  int32     `json:"follower_count"`
+	FollowingCount int32     `json:"following_count"`
+}
+
+// profileParams are the profile fields a user update may carry. Fields that
+// are left out keep their value; an empty avatar_media_id removes the avatar.
+type profileParams struct {
+	Handle        *string `json:"handle"`
+	DisplayName   *string `json:"display_name"`
+	Bio           *string `json:"bio"`
+	AvatarMediaID *string `json:"avatar_media_id"`
+}
+
+func (p profileParams) empty() bool {
+	return p.Handle == nil && p.DisplayName == nil && p.Bio == nil && p.AvatarMediaID == nil
+}
+
+// apply checks the fields that were sent and merges them into user's current
+// profile. Any error it returns is fit to show the client.
+func (p profileParams) apply(user database.User) (database.UpdateUserProfileParams, error) {
+	update := database.UpdateUserProfileParams{
+		ID:            user.ID,
+		Handle:        user.Handle,
+		DisplayName:   user.DisplayName,
+		Bio:           user.Bio,
+		AvatarMediaID: user.AvatarMediaID,
+	}
+
+	if p.Handle != nil {
+		handle := strings.TrimPrefix(*p.Handle, "@")
+		err := entities.ValidateHandle(handle)
+		if err != nil {
+			return database.UpdateUserProfileParams{}, err
+		}
+		update.Handle = sql.NullString{String: handle, Valid: true}
+	}
+
+	if p.DisplayName != nil {
+		displayName := strings.TrimSpace(*p.DisplayName)
+		if utf8.RuneCountInString(displayName) > maxDisplayNameLength {
+			return database.UpdateUserProfileParams{}, errDisplayNameTooLong
+		}
+		update.DisplayName = displayName
+	}
+
+	if p.Bio != nil {
+		bio := strings.TrimSpace(*p.Bio)
+		if utf8.RuneCountInString(bio) > maxBioLength {
+			return database.UpdateUserProfileParams{}, errBioTooLong
+		}
+		update.Bio = bio
+	}
+
+	if p.AvatarMediaID != nil {
+		update.AvatarMediaID = uuid.NullUUID{}
+		if *p.AvatarMediaID != "" {
+			mediaID, err := uuid.Parse(*p.AvatarMediaID)
+			if err != nil {
+				return database.UpdateUserProfileParams{}, errInvalidAvatar
+			}
+			update.AvatarMediaID = uuid.NullUUID{UUID: mediaID, Valid: true}
+		}
+	}
+
+	return update, nil
+}
+
+// updateProfile saves profile and, when it claims a new handle, points the
+// mentions of that handle written before anyone held it at the user, just as
+// if the handle had been theirs when the chirps were posted. It runs inside
+// the caller's transaction.
+func updateProfile(ctx context.Context, qtx *database.Queries, user database.User, profile database.UpdateUserProfileParams) (database.User, error) {
+	updated, err := qtx.UpdateUserProfile(ctx, profile)
+	if err != nil {
+		return database.User{}, err
+	}
+
+	if updated.Handle.Valid && !strings.EqualFold(updated.Handle.String, user.Handle.String) {
+		err = qtx.ResolveChirpMentions(ctx, database.ResolveChirpMentionsParams{
+			UserID: updated.ID,
+			Handle: updated.Handle.String,
+		})
+		if err != nil {
+			return database.User{}, err
+		}
+	}
+
+	return updated, nil
+}
+
+// checkAvatar reports whether mediaID is an image uploaded by userID.
+func (cfg *apiConfig) checkAvatar(ctx context.Context, userID, mediaID uuid.UUID) (bool, error) {
+	files, err := cfg.db.GetUserMediaFiles(ctx, database.GetUserMediaFilesParams{
+		UserID:   userID,
+		MediaIds: []uuid.UUID{mediaID},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return len(files) == 1 && strings.HasPrefix(files[0].ContentType, "image/"), nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// handlerGetProfile looks a user up by ID or by handle, with or without the
+// leading @.
+func (cfg *apiConfig) handlerGetProfile(w http.ResponseWriter, req *http.Request) {
+	userID, err := cfg.resolveUser(req.Context(), req.PathValue("user"))
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "User can't be found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "User can't be retrieved", err)
+		return
+	}
+
+	profile, err := cfg.db.GetUserProfile(req.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "User can't be found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "User can't be retrieved", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, Profile{
+		Author: Author{
+			ID:          profile.ID,
+			Handle:      profile.Handle.String,
+			DisplayName: profile.DisplayName,
+			AvatarURL:   avatarURL(profile.AvatarKey),
+		},
+		Bio:            profile.Bio,
+		CreatedAt:      profile.CreatedAt,
+		FollowerCount:  profile.FollowerCount,
+		FollowingCount: profile.FollowingCount,
+	})
+}
+
+// resolveUser turns a user ID or handle into a user ID.
+func (cfg *apiConfig) resolveUser(ctx context.Context, ref string) (uuid.UUID, error) {
+	userID, err := uuid.Parse(ref)
+	if err == nil {
+		return userID, nil
+	}
+
+	return cfg.db.GetUserIDByHandle(ctx, strings.TrimPrefix(ref, "@"))
+}
+
+// setChirpAuthors embeds each chirp's author.
+func (cfg *apiConfig) setChirpAuthors(ctx context.Context, chirps []*Chirp) error {
+	if len(chirps) == 0 {
+		return nil
+	}
+
+	seen := map[uuid.UUID]bool{}
+	userIDs := []uuid.UUID{}
+	for _, chirp := range chirps {
+		if !seen[chirp.UserID] {
+			seen[chirp.UserID] = true
+			userIDs = append(userIDs, chirp.UserID)
+		}
+	}
+
+	rows, err := cfg.db.GetChirpAuthors(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+
+	authors := make(map[uuid.UUID]*Author, len(rows))
+	for _, row := range rows {
+		authors[row.ID] = &Author{
+			ID:          row.ID,
+			Handle:      row.Handle.String,
+			DisplayName: row.DisplayName,
+			AvatarURL:   avatarURL(row.AvatarKey),
+		}
+	}
+
+	for _, chirp := range chirps {
+		chirp.Author = authors[chirp.UserID]
+	}
+
+	return nil
+}
+
+func avatarURL(storageKey sql.NullString) string {
+	if !storageKey.Valid {
+		return ""
+	}
+	return mediaURL(storageKey.String)
+}
@@ -1,15 +1,23 @@
 package main
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
 )
 
+// handlerRefresh trades a refresh token for a new access token and a new
+// refresh token. Each token can only be used once: presenting one that has
+// already been rotated means two parties hold it, so its whole family is
+// revoked and everyone descended from that login has to sign in again.
 func (cfg *apiConfig) handlerRefresh(w http.ResponseWriter, req *http.Request) {
 	type Response struct {
-		Token string `json:"token"`
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
 	}
 
 	refreshToken, err := auth.GetBearerToken(req.Header)
@@ -18,19 +26,98 @@ func (cfg *apiConfig) handlerRefresh(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	user, err := cfg.db.GetUserFromRefreshToken(req.Context(), refreshToken)
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
 	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to refresh token", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// Locking the row makes two concurrent refreshes with the same token
+	// queue up, so the second one is seen as reuse.
+	current, err := qtx.LockRefreshToken(req.Context(), auth.HashToken(refreshToken))
+	if errors.Is(err, sql.ErrNoRows) {
 		respondWithError(w, http.StatusUnauthorized, "RefreshToken can't be found", err)
 		return
 	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to refresh token", err)
+		return
+	}
+
+	if current.RotatedAt.Valid {
+		err = qtx.RevokeRefreshTokenFamily(req.Context(), current.FamilyID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to refresh token", err)
+			return
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to refresh token", err)
+			return
+		}
+
+		respondWithError(w, http.StatusUnauthorized, "RefreshToken has already been used", nil)
+		return
+	}
+
+	if current.RevokedAt.Valid || !current.ExpiresAt.After(time.Now()) {
+		respondWithError(w, http.StatusUnauthorized, "RefreshToken can't be found", nil)
+		return
+	}
+
+	if !checkNotSuspended(w, req, qtx, current.UserID) {
+		return
+	}
 
-	accessToken, err := auth.MakeJWT(user.ID, cfg.secret, time.Hour)
+	err = qtx.RotateRefreshToken(req.Context(), current.TokenHash)
 	if err != nil {
-		respondWithError(w, http.StatusInsufficientStorage, "Couldn't make access token", err)
+		respondWithError(w, http.StatusInternalServerError, "Unable to refresh token", err)
+		return
+	}
+
+	newRefreshToken, err := auth.CreateRefreshToken()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to refresh token", err)
+		return
+	}
+
+	_, err = qtx.MakeRefreshToken(req.Context(), database.MakeRefreshTokenParams{
+		TokenHash:       auth.HashToken(newRefreshToken),
+		UserID:          current.UserID,
+		FamilyID:        current.FamilyID,
+		ParentTokenHash: sql.NullString{String: current.TokenHash, Valid: true},
+		UserAgent:       req.UserAgent(),
+		IpAddress:       clientIP(req),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "RefreshToken not added to database", err)
+		return
+	}
+
+	tokenVersion, err := qtx.GetUserTokenVersion(req.Context(), current.UserID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't make access token", err)
+		return
+	}
+
+	accessToken, err := cfg.makeJWT(current.UserID, current.FamilyID, tokenVersion)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't make access token", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to refresh token", err)
+		return
 	}
 
 	respondWithJson(w, http.StatusOK, Response{
-		Token: accessToken,
+		Token:        accessToken,
+		RefreshToken: newRefreshToken,
 	})
 }
 
@@ -41,13 +128,13 @@ func (cfg *apiConfig) handlerRevoke(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	_, err = cfg.db.GetUserFromRefreshToken(req.Context(), token)
+	_, err = cfg.db.GetUserFromRefreshToken(req.Context(), auth.HashToken(token))
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "RefreshToken can't be found to revoke", err)
 		return
 	}
 
-	_, err = cfg.db.RevokeRefreshToken(req.Context(), token)
+	_, err = cfg.db.RevokeRefreshToken(req.Context(), auth.HashToken(token))
 	if err != nil {
 		respondWithError(w, http.StatusUnauthorized, "RefreshToken can't be found to revoke", err)
 		return
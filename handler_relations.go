@@ -0,0 +1,229 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+	"github.com/willthefoollearn/chirpy/internal/pagination"
+)
+
+// Blocking hides both users' chirps from each other and stops the blocked
+// user replying to, mentioning, liking, reposting or following the blocker.
+// Muting only hides the muted user's chirps from the muter, and they never
+// find out.
+const (
+	relationBlock = "block"
+	relationMute  = "mute"
+)
+
+type UserRelation struct {
+	UserID    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (cfg *apiConfig) handlerBlock(w http.ResponseWriter, req *http.Request) {
+	cfg.addRelation(w, req, relationBlock)
+}
+
+func (cfg *apiConfig) handlerUnblock(w http.ResponseWriter, req *http.Request) {
+	cfg.removeRelation(w, req, relationBlock)
+}
+
+func (cfg *apiConfig) handlerMute(w http.ResponseWriter, req *http.Request) {
+	cfg.addRelation(w, req, relationMute)
+}
+
+func (cfg *apiConfig) handlerUnmute(w http.ResponseWriter, req *http.Request) {
+	cfg.removeRelation(w, req, relationMute)
+}
+
+func (cfg *apiConfig) handlerListBlocks(w http.ResponseWriter, req *http.Request) {
+	cfg.listRelations(w, req, relationBlock)
+}
+
+func (cfg *apiConfig) handlerListMutes(w http.ResponseWriter, req *http.Request) {
+	cfg.listRelations(w, req, relationMute)
+}
+
+func (cfg *apiConfig) addRelation(w http.ResponseWriter, req *http.Request, kind string) {
+	target, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	user, err := cfg.authorize(req.Context(), accessToken, auth.ScopeProfileWrite)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	if target == user {
+		respondWithError(w, http.StatusBadRequest, "Users can't "+kind+" themselves", nil)
+		return
+	}
+
+	_, err = cfg.db.GetUser(req.Context(), target)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Unable to find user", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to "+kind+" user", err)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to "+kind+" user", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	err = qtx.AddUserRelation(req.Context(), database.AddUserRelationParams{
+		UserID:   user,
+		TargetID: target,
+		Kind:     kind,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to "+kind+" user", err)
+		return
+	}
+
+	// A block ends any follow between the two, in both directions.
+	if kind == relationBlock {
+		err = qtx.RemoveFollowsBetween(req.Context(), database.RemoveFollowsBetweenParams{
+			UserA: user,
+			UserB: target,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to block user", err)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to "+kind+" user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) removeRelation(w http.ResponseWriter, req *http.Request, kind string) {
+	target, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	user, err := cfg.authorize(req.Context(), accessToken, auth.ScopeProfileWrite)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	err = cfg.db.RemoveUserRelation(req.Context(), database.RemoveUserRelationParams{
+		UserID:   user,
+		TargetID: target,
+		Kind:     kind,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to un"+kind+" user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listRelations lists the users the caller has blocked or muted. Nobody else
+// can see these lists.
+func (cfg *apiConfig) listRelations(w http.ResponseWriter, req *http.Request, kind string) {
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	principal, err := cfg.authenticate(req.Context(), accessToken)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+	user := principal.UserID
+
+	page, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid pagination parameters", err)
+		return
+	}
+
+	relations, err := cfg.db.ListUserRelations(req.Context(), database.ListUserRelationsParams{
+		UserID:         user,
+		Kind:           kind,
+		AfterCreatedAt: page.AfterCreatedAt,
+		AfterID:        page.AfterID,
+		RowLimit:       page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve "+kind+"s", err)
+		return
+	}
+
+	if len(relations) > int(page.Limit) {
+		relations = relations[:page.Limit]
+		last := relations[len(relations)-1]
+		setNextPageLink(w, req, pagination.Cursor{
+			CreatedAt: last.CreatedAt,
+			ID:        last.TargetID,
+		})
+	}
+
+	converted := []UserRelation{}
+	for _, relation := range relations {
+		converted = append(converted, UserRelation{
+			UserID:    relation.TargetID,
+			CreatedAt: relation.CreatedAt,
+		})
+	}
+
+	respondWithJson(w, http.StatusOK, converted)
+}
+
+// checkNotBlocked stops user acting on other's account or chirps when either
+// has blocked the other. It writes the error response itself.
+func checkNotBlocked(w http.ResponseWriter, req *http.Request, db *database.Queries, user, other uuid.UUID) bool {
+	blocked, err := db.IsBlockedBetween(req.Context(), database.IsBlockedBetweenParams{
+		UserA: user,
+		UserB: other,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to check blocks", err)
+		return false
+	}
+	if blocked {
+		respondWithError(w, http.StatusForbidden, "You can't interact with this user", nil)
+		return false
+	}
+
+	return true
+}
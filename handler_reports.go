@@ -0,0 +1,532 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+	"github.com/willthefoollearn/chirpy/internal/pagination"
+)
+
+var reportReasons = map[string]bool{
+	"spam":           true,
+	"harassment":     true,
+	"hate":           true,
+	"violence":       true,
+	"sexual":         true,
+	"self_harm":      true,
+	"impersonation":  true,
+	"misinformation": true,
+	"other":          true,
+}
+
+const maxReportDetailsLength = 1000
+
+const (
+	resolutionActioned  = "actioned"
+	resolutionDismissed = "dismissed"
+)
+
+type Report struct {
+	ID         uuid.UUID  `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReporterID *uuid.UUID `json:"reporter_id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	ChirpID    *uuid.UUID `json:"chirp_id"`
+	Reason     string     `json:"reason"`
+	Details    string     `json:"details"`
+	AssignedTo *uuid.UUID `json:"assigned_to"`
+	AssignedAt *time.Time `json:"assigned_at,omitempty"`
+	Resolution string     `json:"resolution,omitempty"`
+	ResolvedBy *uuid.UUID `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+func databaseReportToReport(report database.Report) Report {
+	converted := Report{
+		ID:         report.ID,
+		CreatedAt:  report.CreatedAt,
+		UserID:     report.UserID,
+		Reason:     report.Reason,
+		Details:    report.Details,
+		Resolution: report.Resolution.String,
+	}
+	if report.ReporterID.Valid {
+		converted.ReporterID = &report.ReporterID.UUID
+	}
+	if report.ChirpID.Valid {
+		converted.ChirpID = &report.ChirpID.UUID
+	}
+	if report.AssignedTo.Valid {
+		converted.AssignedTo = &report.AssignedTo.UUID
+	}
+	if report.AssignedAt.Valid {
+		converted.AssignedAt = &report.AssignedAt.Time
+	}
+	if report.ResolvedBy.Valid {
+		converted.ResolvedBy = &report.ResolvedBy.UUID
+	}
+	if report.ResolvedAt.Valid {
+		converted.ResolvedAt = &report.ResolvedAt.Time
+	}
+
+	return converted
+}
+
+type AuditEvent struct {
+	ID         uuid.UUID `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	ActorID    uuid.UUID `json:"actor_id"`
+	Action     string    `json:"action"`
+	TargetType string    `json:"target_type"`
+	TargetID   string    `json:"target_id"`
+	Note       string    `json:"note"`
+}
+
+func (cfg *apiConfig) handlerReportChirp(w http.ResponseWriter, req *http.Request) {
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	reporter, ok := cfg.reporter(w, req)
+	if !ok {
+		return
+	}
+
+	chirp, err := cfg.db.RetrieveChirp(req.Context(), chirpID)
+	if err != nil || chirp.DeletedAt.Valid || !chirp.PublishedAt.Valid {
+		respondWithError(w, http.StatusNotFound, "Can't retrieve Chirp", err)
+		return
+	}
+
+	// Only a takedown hides the chirp here. Blocks and mutes don't, since
+	// blocking the author and then reporting them is the usual order.
+	removed, err := cfg.db.RemovedForViewer(req.Context(), database.RemovedForViewerParams{
+		ViewerID: uuid.NullUUID{UUID: reporter, Valid: true},
+		AuthorID: chirp.UserID,
+		HiddenAt: chirp.HiddenAt,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to report Chirp", err)
+		return
+	}
+	if removed {
+		respondWithError(w, http.StatusNotFound, "Can't retrieve Chirp", nil)
+		return
+	}
+
+	if chirp.UserID == reporter {
+		respondWithError(w, http.StatusBadRequest, "Users can't report their own Chirps", nil)
+		return
+	}
+
+	cfg.createReport(w, req, reporter, chirp.UserID, uuid.NullUUID{UUID: chirp.ID, Valid: true})
+}
+
+func (cfg *apiConfig) handlerReportUser(w http.ResponseWriter, req *http.Request) {
+	userID, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	reporter, ok := cfg.reporter(w, req)
+	if !ok {
+		return
+	}
+
+	if userID == reporter {
+		respondWithError(w, http.StatusBadRequest, "Users can't report themselves", nil)
+		return
+	}
+
+	_, err = cfg.db.GetUser(req.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Unable to find user", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to report user", err)
+		return
+	}
+
+	cfg.createReport(w, req, reporter, userID, uuid.NullUUID{})
+}
+
+// reporter authenticates whoever is filing a report. Reporting is a social
+// action like following or blocking, so it takes the profile:write scope. It
+// writes the error response itself.
+func (cfg *apiConfig) reporter(w http.ResponseWriter, req *http.Request) (uuid.UUID, bool) {
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return uuid.Nil, false
+	}
+
+	user, err := cfg.authorize(req.Context(), accessToken, auth.ScopeProfileWrite)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return uuid.Nil, false
+	}
+
+	return user, true
+}
+
+func (cfg *apiConfig) createReport(w http.ResponseWriter, req *http.Request, reporter, userID uuid.UUID, chirpID uuid.NullUUID) {
+	type parameters struct {
+		Reason  string `json:"reason"`
+		Details string `json:"details"`
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	if !reportReasons[params.Reason] {
+		respondWithError(w, http.StatusBadRequest, "Unknown report reason", nil)
+		return
+	}
+	if utf8.RuneCountInString(params.Details) > maxReportDetailsLength {
+		respondWithError(w, http.StatusBadRequest, "Report details are too long", nil)
+		return
+	}
+
+	report, err := cfg.db.CreateReport(req.Context(), database.CreateReportParams{
+		ReporterID: uuid.NullUUID{UUID: reporter, Valid: true},
+		UserID:     userID,
+		ChirpID:    chirpID,
+		Reason:     params.Reason,
+		Details:    params.Details,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to create report", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusCreated, databaseReportToReport(report))
+}
+
+// handlerListReports is the moderation queue, oldest first. ?resolved=true
+// lists closed reports instead, and ?assigned_to=me or a user ID narrows it
+// to one moderator's reports.
+func (cfg *apiConfig) handlerListReports(w http.ResponseWriter, req *http.Request) {
+	page, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid pagination parameters", err)
+		return
+	}
+
+	resolved := req.URL.Query().Get("resolved") == "true"
+
+	assignedTo := uuid.NullUUID{}
+	switch rawAssignee := req.URL.Query().Get("assigned_to"); rawAssignee {
+	case "":
+	case "me":
+		assignedTo = uuid.NullUUID{UUID: adminFromContext(req.Context()), Valid: true}
+	default:
+		assignee, err := uuid.Parse(rawAssignee)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid assigned_to", err)
+			return
+		}
+		assignedTo = uuid.NullUUID{UUID: assignee, Valid: true}
+	}
+
+	reports, err := cfg.db.ListReports(req.Context(), database.ListReportsParams{
+		Resolved:       resolved,
+		AssignedTo:     assignedTo,
+		AfterCreatedAt: page.AfterCreatedAt,
+		AfterID:        page.AfterID,
+		RowLimit:       page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve reports", err)
+		return
+	}
+
+	if len(reports) > int(page.Limit) {
+		reports = reports[:page.Limit]
+		last := reports[len(reports)-1]
+		setNextPageLink(w, req, pagination.Cursor{
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+		})
+	}
+
+	convertedReports := []Report{}
+	for _, report := range reports {
+		convertedReports = append(convertedReports, databaseReportToReport(report))
+	}
+
+	respondWithJson(w, http.StatusOK, convertedReports)
+}
+
+// handlerAssignReport hands an open report to a moderator, the caller unless
+// the body names someone else.
+func (cfg *apiConfig) handlerAssignReport(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		AssigneeID *uuid.UUID `json:"assignee_id"`
+	}
+
+	reportID, err := uuid.Parse(req.PathValue("reportID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	admin := adminFromContext(req.Context())
+	assignee := admin
+	if params.AssigneeID != nil {
+		assignee = *params.AssigneeID
+	}
+
+	role, err := cfg.db.GetUserRole(req.Context(), assignee)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusInternalServerError, "Unable to assign report", err)
+		return
+	}
+	if !auth.Role(role).AtLeast(auth.RoleModerator) {
+		respondWithError(w, http.StatusBadRequest, "Reports can only be assigned to moderators", err)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to assign report", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	report, err := qtx.AssignReport(req.Context(), database.AssignReportParams{
+		ID:         reportID,
+		AssignedTo: uuid.NullUUID{UUID: assignee, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Unable to find open report", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to assign report", err)
+		return
+	}
+
+	err = recordAudit(req.Context(), qtx, admin, "report.assign", "report", report.ID.String(), assignee.String())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to assign report", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to assign report", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, databaseReportToReport(report))
+}
+
+// handlerResolveReport closes a report as either actioned or dismissed. The
+// action itself, hiding a chirp or suspending a user, is a separate call.
+func (cfg *apiConfig) handlerResolveReport(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Resolution string `json:"resolution"`
+		Note       string `json:"note"`
+	}
+
+	reportID, err := uuid.Parse(req.PathValue("reportID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	if params.Resolution != resolutionActioned && params.Resolution != resolutionDismissed {
+		respondWithError(w, http.StatusBadRequest, "Resolution must be actioned or dismissed", nil)
+		return
+	}
+
+	admin := adminFromContext(req.Context())
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to resolve report", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	report, err := qtx.ResolveReport(req.Context(), database.ResolveReportParams{
+		ID:         reportID,
+		Resolution: sql.NullString{String: params.Resolution, Valid: true},
+		ResolvedBy: uuid.NullUUID{UUID: admin, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Unable to find open report", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to resolve report", err)
+		return
+	}
+
+	err = recordAudit(req.Context(), qtx, admin, "report."+params.Resolution, "report", report.ID.String(), params.Note)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to resolve report", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to resolve report", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, databaseReportToReport(report))
+}
+
+func (cfg *apiConfig) handlerHideChirp(w http.ResponseWriter, req *http.Request) {
+	cfg.setChirpHidden(w, req, true)
+}
+
+func (cfg *apiConfig) handlerUnhideChirp(w http.ResponseWriter, req *http.Request) {
+	cfg.setChirpHidden(w, req, false)
+}
+
+// setChirpHidden takes a chirp down, or puts it back. A hidden chirp drops
+// out of every feed except for its author and moderators.
+func (cfg *apiConfig) setChirpHidden(w http.ResponseWriter, req *http.Request, hide bool) {
+	type parameters struct {
+		Note string `json:"note"`
+	}
+
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to update Chirp", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	action := "chirp.hide"
+	update := qtx.HideChirp
+	if !hide {
+		action = "chirp.unhide"
+		update = qtx.UnhideChirp
+	}
+
+	chirp, err := update(req.Context(), chirpID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Can't retrieve Chirp", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to update Chirp", err)
+		return
+	}
+
+	err = recordAudit(req.Context(), qtx, adminFromContext(req.Context()), action, "chirp", chirp.ID.String(), params.Note)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to update Chirp", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to update Chirp", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlerListAuditEvents reads the audit trail, newest first. ?target_id=
+// narrows it to one report, chirp, user or moderation word.
+func (cfg *apiConfig) handlerListAuditEvents(w http.ResponseWriter, req *http.Request) {
+	page, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid pagination parameters", err)
+		return
+	}
+
+	targetID := sql.NullString{}
+	if rawTarget := req.URL.Query().Get("target_id"); rawTarget != "" {
+		targetID = sql.NullString{String: rawTarget, Valid: true}
+	}
+
+	events, err := cfg.db.ListAuditEvents(req.Context(), database.ListAuditEventsParams{
+		TargetID:       targetID,
+		AfterCreatedAt: page.AfterCreatedAt,
+		AfterID:        page.AfterID,
+		RowLimit:       page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve audit events", err)
+		return
+	}
+
+	if len(events) > int(page.Limit) {
+		events = events[:page.Limit]
+		last := events[len(events)-1]
+		setNextPageLink(w, req, pagination.Cursor{
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+		})
+	}
+
+	convertedEvents := []AuditEvent{}
+	for _, event := range events {
+		convertedEvents = append(convertedEvents, AuditEvent{
+			ID:         event.ID,
+			CreatedAt:  event.CreatedAt,
+			ActorID:    event.ActorID,
+			Action:     event.Action,
+			TargetType: event.TargetType,
+			TargetID:   event.TargetID,
+			Note:       event.Note,
+		})
+	}
+
+	respondWithJson(w, http.StatusOK, convertedEvents)
+}
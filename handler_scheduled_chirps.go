@@ -0,0 +1,228 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+)
+
+func (cfg *apiConfig) handlerListScheduledChirps(w http.ResponseWriter, req *http.Request) {
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	// Drafts are unpublished writing, so listing them takes the same scope
+	// as editing them.
+	user, err := cfg.authorize(req.Context(), accessToken, auth.ScopeChirpsWrite)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	chirps, err := cfg.db.ListUnpublishedChirps(req.Context(), user)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve scheduled Chirps", err)
+		return
+	}
+
+	convertedChirps := make([]Chirp, 0, len(chirps))
+	for _, chirp := range chirps {
+		convertedChirps = append(convertedChirps, databaseChirpToChirp(chirp))
+	}
+
+	viewerChirps := make([]*Chirp, 0, len(convertedChirps))
+	for i := range convertedChirps {
+		viewerChirps = append(viewerChirps, &convertedChirps[i])
+	}
+	err = cfg.decorateChirps(req.Context(), uuid.NullUUID{}, viewerChirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve scheduled Chirps", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, convertedChirps)
+}
+
+// handlerEditScheduledChirp replaces the body and schedule of a draft or
+// scheduled chirp. Clearing both the draft flag and the publish time
+// publishes it immediately.
+func (cfg *apiConfig) handlerEditScheduledChirp(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Body      string     `json:"body"`
+		Draft     bool       `json:"draft"`
+		PublishAt *time.Time `json:"publish_at"`
+	}
+
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	user, err := cfg.authorize(req.Context(), accessToken, auth.ScopeChirpsWrite)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode Chirp parameters", err)
+		return
+	}
+
+	publishAt, publishNow, err := parsePublishTime(params.Draft, params.PublishAt)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid publish time", err)
+		return
+	}
+
+	verdict := cfg.moderator.Moderate(params.Body)
+	if verdict.Rejected {
+		respondWithError(w, http.StatusBadRequest, verdict.Reason(), nil)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to edit Chirp", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// The lock also keeps the scheduler from publishing the chirp mid-edit.
+	chirp, err := qtx.LockChirp(req.Context(), chirpID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && chirp.PublishedAt.Valid) {
+		respondWithError(w, http.StatusNotFound, "Can't retrieve scheduled Chirp", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to edit Chirp", err)
+		return
+	}
+
+	if chirp.UserID != user {
+		respondWithError(w, http.StatusForbidden, "Incorrect user for Chirp", nil)
+		return
+	}
+
+	chirp, err = qtx.UpdateUnpublishedChirp(req.Context(), database.UpdateUnpublishedChirpParams{
+		ID:        chirp.ID,
+		Body:      verdict.Body,
+		PublishAt: publishAt,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to edit Chirp", err)
+		return
+	}
+
+	if publishNow {
+		chirp, err = publishChirp(req.Context(), qtx, chirp.ID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to publish Chirp", err)
+			return
+		}
+	}
+
+	if verdict.Flagged {
+		err = qtx.CreateModerationFlag(req.Context(), database.CreateModerationFlagParams{
+			ChirpID: chirp.ID,
+			Reason:  verdict.Reason(),
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to edit Chirp", err)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to edit Chirp", err)
+		return
+	}
+
+	convertedChirp := databaseChirpToChirp(chirp)
+	err = cfg.decorateChirps(req.Context(), uuid.NullUUID{}, []*Chirp{&convertedChirp})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve Chirp", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, convertedChirp)
+}
+
+func (cfg *apiConfig) handlerCancelScheduledChirp(w http.ResponseWriter, req *http.Request) {
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	user, err := cfg.authorize(req.Context(), accessToken, auth.ScopeChirpsWrite)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to cancel Chirp", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// The media comes off first, since deleting the chirp would take the
+	// links with it. Nothing is kept unless the chirp turns out to be the
+	// user's own unpublished one.
+	mediaKeys, err := detachChirpMedia(req.Context(), qtx, chirpID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to cancel Chirp", err)
+		return
+	}
+
+	deleted, err := qtx.DeleteUnpublishedChirp(req.Context(), database.DeleteUnpublishedChirpParams{
+		ID:     chirpID,
+		UserID: user,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to cancel Chirp", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Can't retrieve scheduled Chirp", nil)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to cancel Chirp", err)
+		return
+	}
+	cfg.deleteStoredMedia(req.Context(), mediaKeys)
+
+	w.WriteHeader(http.StatusNoContent)
+}
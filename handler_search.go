@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/database"
+	"github.com/willthefoollearn/chirpy/internal/pagination"
+)
+
+type SearchResult struct {
+	Chirp
+	Rank    float32 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+type searchParams struct {
+	Query    string
+	AuthorID uuid.NullUUID
+	Since    sql.NullTime
+	Until    sql.NullTime
+	OrderBy  string
+	Sort     string
+}
+
+// handlerSearchChirps runs a full-text search. Besides q it accepts the same
+// author_id parameter as GET /api/chirps, since and until RFC 3339
+// timestamps, and order=relevance (the default) or order=recency. With
+// order=recency, sort=asc or sort=desc works as on GET /api/chirps, except
+// that it defaults to desc, since newest first is what a search usually wants.
+func (cfg *apiConfig) handlerSearchChirps(w http.ResponseWriter, req *http.Request) {
+	search, err := parseSearchParams(req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	page, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid pagination parameters", err)
+		return
+	}
+	if search.OrderBy == "relevance" && page.AfterID.Valid && !page.AfterRank.Valid {
+		respondWithError(w, http.StatusBadRequest, "Cursor doesn't belong to a relevance search", nil)
+		return
+	}
+
+	viewer := cfg.viewerID(req)
+	rows, err := cfg.searchChirps(req.Context(), viewer, search, page)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't search Chirps", err)
+		return
+	}
+
+	if len(rows) > int(page.Limit) {
+		rows = rows[:page.Limit]
+		last := rows[len(rows)-1]
+		cursor := pagination.Cursor{
+			CreatedAt: last.Chirp.CreatedAt,
+			ID:        last.Chirp.ID,
+		}
+		if search.OrderBy == "relevance" {
+			cursor.Rank = &last.Rank
+		}
+		setNextPageLink(w, req, cursor)
+	}
+
+	results := []SearchResult{}
+	for _, row := range rows {
+		results = append(results, SearchResult{
+			Chirp:   databaseChirpToChirp(row.Chirp),
+			Rank:    row.Rank,
+			Snippet: row.Snippet,
+		})
+	}
+
+	viewerChirps := make([]*Chirp, 0, len(results))
+	for i := range results {
+		viewerChirps = append(viewerChirps, &results[i].Chirp)
+	}
+	err = cfg.decorateChirps(req.Context(), viewer, viewerChirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't search Chirps", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, results)
+}
+
+func parseSearchParams(req *http.Request) (searchParams, error) {
+	query := req.URL.Query()
+	search := searchParams{
+		Query:   strings.TrimSpace(query.Get("q")),
+		OrderBy: query.Get("order"),
+		Sort:    query.Get("sort"),
+	}
+
+	if search.Query == "" {
+		return searchParams{}, errors.New("Missing search query")
+	}
+
+	if search.OrderBy == "" {
+		search.OrderBy = "relevance"
+	}
+	if search.OrderBy != "relevance" && search.OrderBy != "recency" {
+		return searchParams{}, errors.New("order must be relevance or recency")
+	}
+	if search.Sort != "desc" && search.Sort != "asc" {
+		search.Sort = "desc"
+	}
+
+	if rawAuthor := query.Get("author_id"); rawAuthor != "" {
+		author, err := uuid.Parse(rawAuthor)
+		if err != nil {
+			return searchParams{}, errors.New("Can't parse author_id")
+		}
+		search.AuthorID = uuid.NullUUID{UUID: author, Valid: true}
+	}
+
+	if rawSince := query.Get("since"); rawSince != "" {
+		since, err := time.Parse(time.RFC3339, rawSince)
+		if err != nil {
+			return searchParams{}, errors.New("since must be an RFC 3339 timestamp")
+		}
+		search.Since = sql.NullTime{Time: since.UTC(), Valid: true}
+	}
+
+	if rawUntil := query.Get("until"); rawUntil != "" {
+		until, err := time.Parse(time.RFC3339, rawUntil)
+		if err != nil {
+			return searchParams{}, errors.New("until must be an RFC 3339 timestamp")
+		}
+		search.Until = sql.NullTime{Time: until.UTC(), Valid: true}
+	}
+
+	return search, nil
+}
+
+// searchChirps fetches one page of search results plus one extra row, in the
+// order asked for.
+func (cfg *apiConfig) searchChirps(ctx context.Context, viewer uuid.NullUUID, search searchParams, page pageParams) ([]database.SearchChirpsByRelevanceRow, error) {
+	if search.OrderBy == "relevance" {
+		return cfg.db.SearchChirpsByRelevance(ctx, database.SearchChirpsByRelevanceParams{
+			Query:          search.Query,
+			ViewerID:       viewer,
+			AuthorID:       search.AuthorID,
+			Since:          search.Since,
+			Until:          search.Until,
+			AfterCreatedAt: page.AfterCreatedAt,
+			AfterRank:      page.AfterRank,
+			AfterID:        page.AfterID,
+			RowLimit:       page.Limit + 1,
+		})
+	}
+
+	rows := []database.SearchChirpsByRelevanceRow{}
+	if search.Sort == "asc" {
+		ascRows, err := cfg.db.SearchChirpsAsc(ctx, database.SearchChirpsAscParams{
+			Query:          search.Query,
+			ViewerID:       viewer,
+			AuthorID:       search.AuthorID,
+			Since:          search.Since,
+			Until:          search.Until,
+			AfterCreatedAt: page.AfterCreatedAt,
+			AfterID:        page.AfterID,
+			RowLimit:       page.Limit + 1,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range ascRows {
+			rows = append(rows, database.SearchChirpsByRelevanceRow(row))
+		}
+		return rows, nil
+	}
+
+	descRows, err := cfg.db.SearchChirpsDesc(ctx, database.SearchChirpsDescParams{
+		Query:          search.Query,
+		ViewerID:       viewer,
+		AuthorID:       search.AuthorID,
+		Since:          search.Since,
+		Until:          search.Until,
+		AfterCreatedAt: page.AfterCreatedAt,
+		AfterID:        page.AfterID,
+		RowLimit:       page.Limit + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range descRows {
+		rows = append(rows, database.SearchChirpsByRelevanceRow(row))
+	}
+	return rows, nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+)
+
+// Session is one login, tracked through the chain of refresh tokens it has
+// been rotated through. Its ID is the refresh token family.
+type Session struct {
+	ID         uuid.UUID `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+}
+
+// clientIP is the address the request came from, without the port.
+func clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+func (cfg *apiConfig) handlerListSessions(w http.ResponseWriter, req *http.Request) {
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	user, err := cfg.authorize(req.Context(), accessToken, auth.ScopeAccount)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	rows, err := cfg.db.ListUserSessions(req.Context(), user)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve sessions", err)
+		return
+	}
+
+	sessions := make([]Session, 0, len(rows))
+	for _, row := range rows {
+		sessions = append(sessions, Session{
+			ID:         row.FamilyID,
+			CreatedAt:  row.StartedAt,
+			LastUsedAt: row.LastUsedAt,
+			ExpiresAt:  row.ExpiresAt,
+			UserAgent:  row.UserAgent,
+			IPAddress:  row.IpAddress,
+		})
+	}
+
+	respondWithJson(w, http.StatusOK, sessions)
+}
+
+// handlerRevokeSession logs a single device out. Its access token keeps
+// working until it expires; use revoke-all to cut those off too.
+func (cfg *apiConfig) handlerRevokeSession(w http.ResponseWriter, req *http.Request) {
+	sessionID, err := uuid.Parse(req.PathValue("sessionID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	user, err := cfg.authorize(req.Context(), accessToken, auth.ScopeAccount)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	revoked, err := cfg.db.RevokeUserSession(req.Context(), database.RevokeUserSessionParams{
+		FamilyID: sessionID,
+		UserID:   user,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to revoke session", err)
+		return
+	}
+	if revoked == 0 {
+		respondWithError(w, http.StatusNotFound, "Can't find session", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlerRevokeAllSessions logs the user out everywhere. Bumping the token
+// version invalidates every access token already handed out, not just the
+// refresh tokens. Personal access tokens are left alone; they are revoked
+// one at a time through /api/tokens.
+func (cfg *apiConfig) handlerRevokeAllSessions(w http.ResponseWriter, req *http.Request) {
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	user, err := cfg.authorize(req.Context(), accessToken, auth.ScopeAccount)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to revoke sessions", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	err = qtx.RevokeAllUserSessions(req.Context(), user)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to revoke sessions", err)
+		return
+	}
+
+	_, err = qtx.IncrementTokenVersion(req.Context(), user)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to revoke sessions", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to revoke sessions", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/database"
+)
+
+const (
+	defaultThreadDepth = 5
+	maxThreadDepth     = 20
+	maxThreadSize      = 500
+)
+
+type ThreadChirp struct {
+	Chirp
+	Depth   int32          `json:"depth"`
+	Replies []*ThreadChirp `json:"replies"`
+}
+
+func (cfg *apiConfig) handlerChirpThread(w http.ResponseWriter, req *http.Request) {
+	path := req.PathValue("chirpID")
+	rootID, err := uuid.Parse(path)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	depth := defaultThreadDepth
+	if rawDepth := req.URL.Query().Get("depth"); rawDepth != "" {
+		depth, err = strconv.Atoi(rawDepth)
+		if err != nil || depth < 0 {
+			respondWithError(w, http.StatusBadRequest, "depth must be a non-negative integer", err)
+			return
+		}
+		if depth > maxThreadDepth {
+			depth = maxThreadDepth
+		}
+	}
+
+	sortMethod := req.URL.Query().Get("sort")
+	if sortMethod != "desc" {
+		sortMethod = "asc"
+	}
+
+	// Replies the viewer shouldn't see are left out along with everything
+	// below them.
+	viewer := cfg.viewerID(req)
+	rows, err := cfg.db.GetChirpThread(req.Context(), database.GetChirpThreadParams{
+		RootID:   rootID,
+		ViewerID: viewer,
+		MaxDepth: int32(depth),
+		RowLimit: maxThreadSize,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve thread", err)
+		return
+	}
+	if len(rows) == 0 {
+		respondWithError(w, http.StatusNotFound, "Can't retrieve Chirp", nil)
+		return
+	}
+
+	// Rows arrive parents-first and oldest-first within each level, so every
+	// reply's parent has already been placed by the time we see it.
+	nodes := make(map[uuid.UUID]*ThreadChirp, len(rows))
+	var root *ThreadChirp
+	for _, row := range rows {
+		node := &ThreadChirp{
+			Chirp:   databaseChirpToChirp(row.Chirp),
+			Depth:   row.Depth,
+			Replies: []*ThreadChirp{},
+		}
+		nodes[row.Chirp.ID] = node
+
+		if row.Depth == 0 {
+			root = node
+			continue
+		}
+
+		parent, ok := nodes[row.Chirp.ParentID.UUID]
+		if !ok {
+			continue
+		}
+		parent.Replies = append(parent.Replies, node)
+	}
+
+	viewerChirps := make([]*Chirp, 0, len(nodes))
+	for _, node := range nodes {
+		viewerChirps = append(viewerChirps, &node.Chirp)
+	}
+	err = cfg.decorateChirps(req.Context(), viewer, viewerChirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve thread", err)
+		return
+	}
+
+	if sortMethod == "desc" {
+		for _, node := range nodes {
+			for i, j := 0, len(node.Replies)-1; i < j; i, j = i+1, j-1 {
+				node.Replies[i], node.Replies[j] = node.Replies[j], node.Replies[i]
+			}
+		}
+	}
+
+	respondWithJson(w, http.StatusOK, root)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+	"github.com/willthefoollearn/chirpy/internal/pagination"
+)
+
+func (cfg *apiConfig) handlerTimeline(w http.ResponseWriter, req *http.Request) {
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	principal, err := cfg.authenticate(req.Context(), accessToken)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+	user := principal.UserID
+
+	page, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid pagination parameters", err)
+		return
+	}
+
+	chirps, err := cfg.db.ListTimeline(req.Context(), database.ListTimelineParams{
+		UserID:         user,
+		ViewerID:       uuid.NullUUID{UUID: user, Valid: true},
+		AfterCreatedAt: page.AfterCreatedAt,
+		AfterID:        page.AfterID,
+		RowLimit:       page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve timeline", err)
+		return
+	}
+
+	if len(chirps) > int(page.Limit) {
+		chirps = chirps[:page.Limit]
+		last := chirps[len(chirps)-1]
+		setNextPageLink(w, req, pagination.Cursor{
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+		})
+	}
+
+	convertedChirps := []Chirp{}
+	for _, chirp := range chirps {
+		convertedChirps = append(convertedChirps, databaseChirpToChirp(chirp))
+	}
+
+	viewerChirps := make([]*Chirp, 0, len(convertedChirps))
+	for i := range convertedChirps {
+		viewerChirps = append(viewerChirps, &convertedChirps[i])
+	}
+	err = cfg.decorateChirps(req.Context(), uuid.NullUUID{UUID: user, Valid: true}, viewerChirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve timeline", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, convertedChirps)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+)
+
+const maxTokenNameLength = 100
+
+type PersonalAccessToken struct {
+	ID         uuid.UUID  `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	// Token is only ever returned once, when the token is created.
+	Token string `json:"token,omitempty"`
+}
+
+func databaseTokenToToken(token database.PersonalAccessToken) PersonalAccessToken {
+	converted := PersonalAccessToken{
+		ID:        token.ID,
+		CreatedAt: token.CreatedAt,
+		Name:      token.Name,
+		Scopes:    token.Scopes,
+	}
+	if token.ExpiresAt.Valid {
+		converted.ExpiresAt = &token.ExpiresAt.Time
+	}
+	if token.LastUsedAt.Valid {
+		converted.LastUsedAt = &token.LastUsedAt.Time
+	}
+
+	return converted
+}
+
+func (cfg *apiConfig) handlerCreateToken(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Name      string     `json:"name"`
+		Scopes    []string   `json:"scopes"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	user, err := cfg.authorize(req.Context(), accessToken, auth.ScopeAccount)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Unable to decode token parameters", err)
+		return
+	}
+
+	if params.Name == "" || len(params.Name) > maxTokenNameLength {
+		respondWithError(w, http.StatusBadRequest, "Token name must be between 1 and 100 characters", nil)
+		return
+	}
+
+	scopes, err := auth.ParseScopes(params.Scopes, auth.GrantableScopes)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid scopes", err)
+		return
+	}
+
+	expiresAt := sql.NullTime{}
+	if params.ExpiresAt != nil {
+		if !params.ExpiresAt.After(time.Now()) {
+			respondWithError(w, http.StatusBadRequest, "Token expiry must be in the future", nil)
+			return
+		}
+		expiresAt = sql.NullTime{Time: params.ExpiresAt.UTC(), Valid: true}
+	}
+
+	tokenString, err := auth.CreatePersonalAccessToken()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to create token", err)
+		return
+	}
+
+	token, err := cfg.db.CreatePersonalAccessToken(req.Context(), database.CreatePersonalAccessTokenParams{
+		UserID:    user,
+		Name:      params.Name,
+		TokenHash: auth.HashToken(tokenString),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to create token", err)
+		return
+	}
+
+	converted := databaseTokenToToken(token)
+	converted.Token = tokenString
+
+	respondWithJson(w, http.StatusCreated, converted)
+}
+
+func (cfg *apiConfig) handlerListTokens(w http.ResponseWriter, req *http.Request) {
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	user, err := cfg.authorize(req.Context(), accessToken, auth.ScopeAccount)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	tokens, err := cfg.db.ListPersonalAccessTokens(req.Context(), user)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve tokens", err)
+		return
+	}
+
+	converted := make([]PersonalAccessToken, 0, len(tokens))
+	for _, token := range tokens {
+		converted = append(converted, databaseTokenToToken(token))
+	}
+
+	respondWithJson(w, http.StatusOK, converted)
+}
+
+func (cfg *apiConfig) handlerRevokeToken(w http.ResponseWriter, req *http.Request) {
+	tokenID, err := uuid.Parse(req.PathValue("tokenID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	user, err := cfg.authorize(req.Context(), accessToken, auth.ScopeAccount)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	revoked, err := cfg.db.RevokePersonalAccessToken(req.Context(), database.RevokePersonalAccessTokenParams{
+		ID:     tokenID,
+		UserID: user,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to revoke token", err)
+		return
+	}
+	if revoked == 0 {
+		respondWithError(w, http.StatusNotFound, "Can't find token", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,417 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+	"github.com/willthefoollearn/chirpy/internal/totp"
+)
+
+const (
+	totpIssuer        = "Chirpy"
+	recoveryCodeCount = 10
+	maxMFAAttempts    = 5
+)
+
+// verifySecondFactor accepts either a current TOTP code or an unused recovery
+// code. A TOTP code is only good once: the step it matched is recorded and
+// codes from that step or earlier are refused from then on.
+func verifySecondFactor(ctx context.Context, db *database.Queries, user database.User, code, recoveryCode string) (bool, error) {
+	if code != "" {
+		if !user.TotpSecret.Valid {
+			return false, nil
+		}
+
+		step, ok := totp.Verify(user.TotpSecret.String, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+
+		used, err := db.UseTOTPStep(ctx, database.UseTOTPStepParams{
+			ID:           user.ID,
+			TotpLastStep: step,
+		})
+		return used == 1, err
+	}
+
+	if recoveryCode != "" {
+		used, err := db.UseRecoveryCode(ctx, database.UseRecoveryCodeParams{
+			UserID:   user.ID,
+			CodeHash: auth.HashRecoveryCode(recoveryCode),
+		})
+		return used == 1, err
+	}
+
+	return false, nil
+}
+
+// respondWithMFAChallenge is the first half of a login with two-factor
+// authentication on. Instead of tokens the user gets a short-lived challenge
+// to redeem at /api/login/mfa along with a code.
+func (cfg *apiConfig) respondWithMFAChallenge(w http.ResponseWriter, req *http.Request, user database.User) {
+	type response struct {
+		MFARequired bool      `json:"mfa_required"`
+		MFAToken    string    `json:"mfa_token"`
+		ExpiresAt   time.Time `json:"expires_at"`
+	}
+
+	mfaToken, err := auth.CreateRefreshToken()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to start two-factor login", err)
+		return
+	}
+
+	challenge, err := cfg.db.CreateMFAChallenge(req.Context(), database.CreateMFAChallengeParams{
+		TokenHash: auth.HashToken(mfaToken),
+		UserID:    user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to start two-factor login", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, response{
+		MFARequired: true,
+		MFAToken:    mfaToken,
+		ExpiresAt:   challenge.ExpiresAt,
+	})
+}
+
+func (cfg *apiConfig) handlerLoginMFA(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		MFAToken     string `json:"mfa_token"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recovery_code"`
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parameters couldn't be decoded", err)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to log in", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	challenge, err := qtx.LockMFAChallenge(req.Context(), auth.HashToken(params.MFAToken))
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusUnauthorized, "Two-factor challenge is invalid or expired", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to log in", err)
+		return
+	}
+	if challenge.UsedAt.Valid || !challenge.ExpiresAt.After(time.Now()) || challenge.Attempts >= maxMFAAttempts {
+		respondWithError(w, http.StatusUnauthorized, "Two-factor challenge is invalid or expired", nil)
+		return
+	}
+
+	err = qtx.RecordMFAAttempt(req.Context(), challenge.TokenHash)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to log in", err)
+		return
+	}
+
+	user, err := qtx.GetUser(req.Context(), challenge.UserID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to log in", err)
+		return
+	}
+
+	// Codes count against the same account and address as passwords, so
+	// fresh challenges don't buy fresh guesses.
+	throttle := newLoginThrottle(req, user.Email)
+	lockedUntil, err := throttle.begin(req.Context(), cfg.conn, cfg.db)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to log in", err)
+		return
+	}
+	if !lockedUntil.IsZero() {
+		respondWithLoginLocked(w, lockedUntil)
+		return
+	}
+
+	ok, err := verifySecondFactor(req.Context(), qtx, user, params.Code, params.RecoveryCode)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to log in", err)
+		return
+	}
+
+	if ok {
+		err = qtx.UseMFAChallenge(req.Context(), challenge.TokenHash)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to log in", err)
+			return
+		}
+	}
+
+	// Commit either way, so failed attempts count towards the limit.
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to log in", err)
+		return
+	}
+
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Two-factor code is incorrect", nil)
+		return
+	}
+
+	err = throttle.recordSuccess(req.Context(), cfg.db)
+	if err != nil {
+		log.Printf("Unable to clear failed logins: %v", err)
+	}
+
+	if !checkNotSuspended(w, req, cfg.db, user.ID) {
+		return
+	}
+
+	cfg.respondWithSession(w, req, user)
+}
+
+// handlerEnrollTOTP starts two-factor enrollment with a fresh secret. It
+// doesn't take effect until a code from it is confirmed.
+func (cfg *apiConfig) handlerEnrollTOTP(w http.ResponseWriter, req *http.Request) {
+	type response struct {
+		Secret string `json:"secret"`
+		URI    string `json:"otpauth_uri"`
+	}
+
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	userID, err := cfg.authorize(req.Context(), accessToken, auth.ScopeAccount)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	user, err := cfg.db.GetUser(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to enroll", err)
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to enroll", err)
+		return
+	}
+
+	updated, err := cfg.db.SetTOTPSecret(req.Context(), database.SetTOTPSecretParams{
+		ID:         user.ID,
+		TotpSecret: sql.NullString{String: secret, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to enroll", err)
+		return
+	}
+	if updated == 0 {
+		respondWithError(w, http.StatusConflict, "Two-factor authentication is already on", nil)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, response{
+		Secret: secret,
+		URI:    totp.URI(totpIssuer, user.Email, secret),
+	})
+}
+
+// handlerConfirmTOTP turns two-factor authentication on once the user proves
+// their authenticator app is set up, and hands out recovery codes. They are
+// only shown this once.
+func (cfg *apiConfig) handlerConfirmTOTP(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Code string `json:"code"`
+	}
+	type response struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	userID, err := cfg.authorize(req.Context(), accessToken, auth.ScopeAccount)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parameters couldn't be decoded", err)
+		return
+	}
+
+	user, err := cfg.db.GetUser(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to confirm two-factor authentication", err)
+		return
+	}
+	if user.TotpEnabledAt.Valid {
+		respondWithError(w, http.StatusConflict, "Two-factor authentication is already on", nil)
+		return
+	}
+	if !user.TotpSecret.Valid {
+		respondWithError(w, http.StatusBadRequest, "Two-factor enrollment hasn't been started", nil)
+		return
+	}
+
+	step, ok := totp.Verify(user.TotpSecret.String, params.Code, time.Now())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Two-factor code is incorrect", nil)
+		return
+	}
+
+	codes, err := auth.CreateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to confirm two-factor authentication", err)
+		return
+	}
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, auth.HashRecoveryCode(code))
+	}
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to confirm two-factor authentication", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	err = qtx.EnableTOTP(req.Context(), database.EnableTOTPParams{
+		ID:           user.ID,
+		TotpLastStep: step,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to confirm two-factor authentication", err)
+		return
+	}
+
+	err = qtx.DeleteRecoveryCodes(req.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to confirm two-factor authentication", err)
+		return
+	}
+
+	err = qtx.AddRecoveryCodes(req.Context(), database.AddRecoveryCodesParams{
+		CodeHashes: hashes,
+		UserID:     user.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to confirm two-factor authentication", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to confirm two-factor authentication", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, response{
+		RecoveryCodes: codes,
+	})
+}
+
+// handlerDisableTOTP turns two-factor authentication off. It takes a second
+// factor as well as an access token, so a stolen token alone can't remove it.
+func (cfg *apiConfig) handlerDisableTOTP(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recovery_code"`
+	}
+
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "BearerToken unable to be retrieved", err)
+		return
+	}
+
+	userID, err := cfg.authorize(req.Context(), accessToken, auth.ScopeAccount)
+	if err != nil {
+		respondWithAuthError(w, "User can't be found with accessToken", err)
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parameters couldn't be decoded", err)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to turn off two-factor authentication", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	user, err := qtx.GetUser(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to turn off two-factor authentication", err)
+		return
+	}
+	if !user.TotpEnabledAt.Valid {
+		respondWithError(w, http.StatusConflict, "Two-factor authentication is already off", nil)
+		return
+	}
+
+	ok, err := verifySecondFactor(req.Context(), qtx, user, params.Code, params.RecoveryCode)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to turn off two-factor authentication", err)
+		return
+	}
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "Two-factor code is incorrect", nil)
+		return
+	}
+
+	err = qtx.DisableTOTP(req.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to turn off two-factor authentication", err)
+		return
+	}
+
+	err = qtx.DeleteRecoveryCodes(req.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to turn off two-factor authentication", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to turn off two-factor authentication", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/willthefoollearn/chirpy/internal/auth"
 	"github.com/willthefoollearn/chirpy/internal/database"
+	"github.com/willthefoollearn/chirpy/internal/entities"
 )
 
 type User struct {
@@ -17,6 +23,41 @@ type User struct {
 	Email       string    `json:"email"`
 	Password    string    `json:"-"`
 	IsChirpyRed bool      `json:"is_chirpy_red"`
+
+	EmailVerified bool   `json:"email_verified"`
+	PendingEmail  string `json:"pending_email,omitempty"`
+
+	Handle        string     `json:"handle,omitempty"`
+	DisplayName   string     `json:"display_name"`
+	Bio           string     `json:"bio"`
+	AvatarMediaID *uuid.UUID `json:"avatar_media_id"`
+
+	DeleteAfter *time.Time `json:"delete_after,omitempty"`
+
+	Role string `json:"role"`
+}
+
+func databaseUserToUser(user database.User) User {
+	converted := User{
+		ID:            user.ID,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+		Email:         user.Email,
+		IsChirpyRed:   user.IsChirpyRed,
+		EmailVerified: user.EmailVerifiedAt.Valid,
+		Handle:        user.Handle.String,
+		DisplayName:   user.DisplayName,
+		Bio:           user.Bio,
+		Role:          user.Role,
+	}
+	if user.AvatarMediaID.Valid {
+		converted.AvatarMediaID = &user.AvatarMediaID.UUID
+	}
+	if user.DeleteAfter.Valid {
+		converted.DeleteAfter = &user.DeleteAfter.Time
+	}
+
+	return converted
 }
 
 type Response struct {
@@ -29,6 +70,7 @@ func (cfg *apiConfig) handlerCreateUser(w http.ResponseWriter, req *http.Request
 	type parameters struct {
 		Password string `json:"password"`
 		Email    string `json:"email"`
+		Handle   string `json:"handle"`
 	}
 
 	decoder := json.NewDecoder(req.Body)
@@ -39,27 +81,77 @@ func (cfg *apiConfig) handlerCreateUser(w http.ResponseWriter, req *http.Request
 		return
 	}
 
+	handle := sql.NullString{}
+	if params.Handle != "" {
+		handle.String = strings.TrimPrefix(params.Handle, "@")
+		handle.Valid = true
+
+		err = entities.ValidateHandle(handle.String)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+	}
+
+	err = auth.CheckPasswordStrength(params.Password, params.Email)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
 	hashedPassword, err := auth.HashPassword(params.Password)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't hash password", err)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "User couldn't be created", err)
+		return
 	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
 
-	user, err := cfg.db.CreateUser(req.Context(), database.CreateUserParams{
+	user, err := qtx.CreateUser(req.Context(), database.CreateUserParams{
 		Email:          params.Email,
 		HashedPassword: hashedPassword,
+		Handle:         handle,
 	})
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "Email or handle is already taken", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "User couldn't be created", err)
+		return
+	}
+
+	if handle.Valid {
+		err = qtx.ResolveChirpMentions(req.Context(), database.ResolveChirpMentionsParams{
+			UserID: user.ID,
+			Handle: handle.String,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "User couldn't be created", err)
+			return
+		}
+	}
+
+	err = tx.Commit()
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "User couldn't be created", err)
 		return
 	}
 
+	err = cfg.sendVerificationEmail(req.Context(), user.ID, user.Email)
+	if err != nil {
+		log.Printf("Unable to send verification email to user %s: %v", user.ID, err)
+	}
+
 	respondWithJson(w, http.StatusCreated, Response{
-		User: User{
-			ID:        user.ID,
-			CreatedAt: user.CreatedAt,
-			UpdatedAt: user.UpdatedAt,
-			Email:     user.Email,
-		}})
+		User: databaseUserToUser(user),
+	})
 }
 
 func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, req *http.Request) {
@@ -76,19 +168,62 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	user, err := cfg.db.LookUpUser(req.Context(), params.Email)
+	throttle := newLoginThrottle(req, params.Email)
+	lockedUntil, err := throttle.begin(req.Context(), cfg.conn, cfg.db)
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Can't find user", err)
+		respondWithError(w, http.StatusInternalServerError, "Unable to log in", err)
+		return
+	}
+	if !lockedUntil.IsZero() {
+		respondWithLoginLocked(w, lockedUntil)
 		return
 	}
 
-	err = auth.CheckPasswordHash(params.Password, user.HashedPassword)
+	// An unknown email and a wrong password get the same answer after the
+	// same amount of bcrypt work, so neither reveals whether the account exists.
+	user, err := cfg.db.LookUpUser(req.Context(), params.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = auth.CheckNoPassword(params.Password)
+	} else if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to log in", err)
+		return
+	} else {
+		err = auth.CheckPasswordHash(params.Password, user.HashedPassword)
+	}
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "Password is incorrect", err)
+		respondWithError(w, http.StatusUnauthorized, "Incorrect email or password", err)
+		return
+	}
+
+	if !checkNotSuspended(w, req, cfg.db, user.ID) {
 		return
 	}
 
-	token, err := auth.MakeJWT(user.ID, cfg.secret, time.Hour)
+	// With two-factor on, the password alone doesn't clear the account's
+	// failures: handlerLoginMFA does that once the second factor passes.
+	if user.TotpEnabledAt.Valid {
+		err = throttle.forgiveAddress(req.Context(), cfg.db)
+		if err != nil {
+			log.Printf("Unable to clear failed logins: %v", err)
+		}
+		cfg.respondWithMFAChallenge(w, req, user)
+		return
+	}
+
+	err = throttle.recordSuccess(req.Context(), cfg.db)
+	if err != nil {
+		log.Printf("Unable to clear failed logins: %v", err)
+	}
+
+	cfg.respondWithSession(w, req, user)
+}
+
+// respondWithSession finishes a login: it starts a new refresh token family
+// and hands the user their first access and refresh tokens.
+func (cfg *apiConfig) respondWithSession(w http.ResponseWriter, req *http.Request, user database.User) {
+	sessionID := uuid.New()
+
+	token, err := cfg.makeJWT(user.ID, sessionID, user.TokenVersion)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Token unable to be created", err)
 		return
@@ -97,8 +232,11 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, req *http.Request) {
 	refreshToken, _ := auth.CreateRefreshToken()
 
 	_, err = cfg.db.MakeRefreshToken(req.Context(), database.MakeRefreshTokenParams{
-		Token:  refreshToken,
-		UserID: user.ID,
+		TokenHash: auth.HashToken(refreshToken),
+		UserID:    user.ID,
+		FamilyID:  sessionID,
+		UserAgent: req.UserAgent(),
+		IpAddress: clientIP(req),
 	})
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "RefreshToken not added to database", err)
@@ -106,29 +244,28 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, req *http.Request) {
 	}
 
 	respondWithJson(w, http.StatusOK, Response{
-		User: User{
-			ID:          user.ID,
-			CreatedAt:   user.CreatedAt,
-			UpdatedAt:   user.UpdatedAt,
-			Email:       user.Email,
-			IsChirpyRed: user.IsChirpyRed,
-		},
+		User:         databaseUserToUser(user),
 		Token:        token,
 		RefreshToken: refreshToken,
 	})
 }
 
+// handlerUserUpdate changes only the fields that are sent. Changing the
+// email or password needs the current password too, so a stolen access token
+// isn't enough to take the account over.
 func (cfg *apiConfig) handlerUserUpdate(w http.ResponseWriter, req *http.Request) {
 	type parameters struct {
-		Password string `json:"password"`
-		Email    string `json:"email"`
+		profileParams
+		Email           *string `json:"email"`
+		Password        *string `json:"password"`
+		CurrentPassword string  `json:"current_password"`
 	}
 
 	decoder := json.NewDecoder(req.Body)
 	params := parameters{}
 	err := decoder.Decode(&params)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Parameters couldn't be decoded", err)
+		respondWithError(w, http.StatusBadRequest, "Parameters couldn't be decoded", err)
 		return
 	}
 
@@ -138,35 +275,205 @@ func (cfg *apiConfig) handlerUserUpdate(w http.ResponseWriter, req *http.Request
 		return
 	}
 
-	user, err := auth.ValidateJWT(accessToken, cfg.secret)
+	principal, err := cfg.authenticate(req.Context(), accessToken)
+	if err == nil {
+		err = principal.Require(auth.ScopeProfileWrite)
+	}
 	if err != nil {
-		respondWithError(w, http.StatusUnauthorized, "User can't be found with accessToken", err)
+		respondWithAuthError(w, "User can't be found with accessToken", err)
 		return
 	}
 
-	password, err := auth.HashPassword(params.Password)
+	user, err := cfg.db.GetUser(req.Context(), principal.UserID)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Password couldn't be hashed", err)
+		respondWithError(w, http.StatusInternalServerError, "User couldn't be found", err)
 		return
 	}
 
-	updatedUser, err := cfg.db.UpdateUser(req.Context(), database.UpdateUserParams{
-		ID:             user,
-		Email:          params.Email,
-		HashedPassword: password,
-	})
+	changeEmail := params.Email != nil && *params.Email != user.Email
+	changePassword := params.Password != nil
+	changeProfile := !params.profileParams.empty()
+	if !changeEmail && !changePassword && !changeProfile {
+		respondWithJson(w, http.StatusOK, Response{
+			User: databaseUserToUser(user),
+		})
+		return
+	}
+
+	if changeEmail && *params.Email == "" {
+		respondWithError(w, http.StatusBadRequest, "Email can't be empty", nil)
+		return
+	}
+	if changePassword {
+		err = auth.CheckPasswordStrength(*params.Password, user.Email)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+	}
+
+	profile, err := params.profileParams.apply(user)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if profile.AvatarMediaID.Valid && profile.AvatarMediaID != user.AvatarMediaID {
+		ok, err := cfg.checkAvatar(req.Context(), user.ID, profile.AvatarMediaID.UUID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "User couldn't be updated", err)
+			return
+		}
+		if !ok {
+			respondWithError(w, http.StatusBadRequest, errInvalidAvatar.Error(), nil)
+			return
+		}
+	}
+
+	if changeEmail || changePassword {
+		ok := cfg.checkCurrentPassword(w, req, user, params.CurrentPassword)
+		if !ok {
+			return
+		}
+	}
+
+	hashedPassword := ""
+	if changePassword {
+		hashedPassword, err = auth.HashPassword(*params.Password)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't hash password", err)
+			return
+		}
+	}
+
+	// Every change goes in together or not at all, so an error never leaves
+	// part of the update applied.
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "User couldn't be updated", err)
 		return
 	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
 
-	respondWithJson(w, http.StatusOK, Response{
-		User: User{
-			ID:          updatedUser.ID,
-			CreatedAt:   updatedUser.CreatedAt,
-			UpdatedAt:   updatedUser.UpdatedAt,
-			Email:       updatedUser.Email,
-			IsChirpyRed: updatedUser.IsChirpyRed,
-		},
+	if changeProfile {
+		user, err = updateProfile(req.Context(), qtx, user, profile)
+		if isUniqueViolation(err) {
+			respondWithError(w, http.StatusConflict, "Handle is already taken", err)
+			return
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "User couldn't be updated", err)
+			return
+		}
+	}
+
+	if changePassword {
+		user, err = updatePassword(req.Context(), qtx, user.ID, principal.SessionID, hashedPassword)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Password couldn't be changed", err)
+			return
+		}
+	}
+
+	// A new address only replaces the old one once its owner confirms it.
+	verificationToken := ""
+	if changeEmail {
+		verificationToken, err = issueEmailToken(req.Context(), qtx, user.ID, purposeVerifyEmail, *params.Email, emailVerificationLifetime)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Unable to send verification email", err)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "User couldn't be updated", err)
+		return
+	}
+
+	response := Response{
+		User: databaseUserToUser(user),
+	}
+
+	// The token version moved on, so the caller's own access token needs
+	// replacing too. A personal access token isn't tied to a session and gets
+	// nothing back.
+	if changePassword && principal.SessionID != uuid.Nil {
+		response.Token, err = cfg.makeJWT(user.ID, principal.SessionID, user.TokenVersion)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Token unable to be created", err)
+			return
+		}
+	}
+
+	if changeEmail {
+		cfg.sendVerificationMail(*params.Email, verificationToken)
+		response.PendingEmail = *params.Email
+	}
+
+	respondWithJson(w, http.StatusOK, response)
+}
+
+// checkCurrentPassword confirms the user knows their password before a
+// sensitive change. Wrong guesses count against the account like failed
+// logins do. It writes the error response itself when the check fails.
+func (cfg *apiConfig) checkCurrentPassword(w http.ResponseWriter, req *http.Request, user database.User, password string) bool {
+	throttle := newLoginThrottle(req, user.Email)
+	lockedUntil, err := throttle.begin(req.Context(), cfg.conn, cfg.db)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to check password", err)
+		return false
+	}
+	if !lockedUntil.IsZero() {
+		respondWithLoginLocked(w, lockedUntil)
+		return false
+	}
+
+	err = auth.CheckPasswordHash(password, user.HashedPassword)
+	if err != nil {
+		respondWithError(w, http.StatusForbidden, "Current password is incorrect", err)
+		return false
+	}
+
+	err = throttle.recordSuccess(req.Context(), cfg.db)
+	if err != nil {
+		log.Printf("Unable to clear failed password checks: %v", err)
+	}
+
+	return true
+}
+
+// updatePassword sets a new password and signs the user out everywhere but
+// the session making the change. It runs inside the caller's transaction.
+func updatePassword(ctx context.Context, qtx *database.Queries, userID, sessionID uuid.UUID, hashedPassword string) (database.User, error) {
+	_, err := qtx.UpdatePassword(ctx, database.UpdatePasswordParams{
+		ID:             userID,
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		return database.User{}, err
+	}
+
+	err = qtx.RevokeOtherUserSessions(ctx, database.RevokeOtherUserSessionsParams{
+		UserID:   userID,
+		FamilyID: sessionID,
 	})
+	if err != nil {
+		return database.User{}, err
+	}
+
+	_, err = qtx.IncrementTokenVersion(ctx, userID)
+	if err != nil {
+		return database.User{}, err
+	}
+
+	err = qtx.ExpireEmailTokens(ctx, database.ExpireEmailTokensParams{
+		UserID:  userID,
+		Purpose: purposePasswordReset,
+	})
+	if err != nil {
+		return database.User{}, err
+	}
+
+	return qtx.GetUser(ctx, userID)
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
 )
 
 func (cfg *apiConfig) handlerUpgrade(w http.ResponseWriter, req *http.Request) {
@@ -40,9 +41,35 @@ func (cfg *apiConfig) handlerUpgrade(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	err = cfg.db.RedUpgrade(req.Context(), params.Data.User_ID)
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to upgrade user", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// The event is kept as the user's subscription history. Its foreign key
+	// is also what tells us the user exists.
+	err = qtx.RecordSubscriptionEvent(req.Context(), database.RecordSubscriptionEventParams{
+		UserID: params.Data.User_ID,
+		Event:  params.Event,
+	})
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, "Unable to find user", err)
+		return
+	}
+
+	err = qtx.RedUpgrade(req.Context(), params.Data.User_ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to upgrade user", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to upgrade user", err)
+		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
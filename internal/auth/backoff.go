@@ -0,0 +1,28 @@
+package auth
+
+import "time"
+
+// Backoff says how long to refuse logins after a run of failures. The first
+// Free failures cost nothing; after that the wait starts at Base and doubles
+// with every failure until it reaches Max, at which point the key is locked
+// out for Max at a time.
+type Backoff struct {
+	Free int
+	Base time.Duration
+	Max  time.Duration
+}
+
+func (b Backoff) Delay(failures int) time.Duration {
+	if failures <= b.Free {
+		return 0
+	}
+
+	delay := b.Base
+	for i := b.Free + 1; i < failures; i++ {
+		delay *= 2
+		if delay >= b.Max {
+			return b.Max
+		}
+	}
+	return min(delay, b.Max)
+}
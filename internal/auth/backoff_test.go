@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	backoff := Backoff{Free: 3, Base: time.Second, Max: time.Minute}
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{3, 0},
+		{4, time.Second},
+		{5, 2 * time.Second},
+		{8, 16 * time.Second},
+		{9, 32 * time.Second},
+		{10, time.Minute},
+		{1000, time.Minute},
+	}
+
+	for _, test := range tests {
+		got := backoff.Delay(test.failures)
+		if got != test.want {
+			t.Errorf("Delay(%d) = %v, want %v", test.failures, got, test.want)
+		}
+	}
+}
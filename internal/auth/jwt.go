@@ -11,39 +11,88 @@ import (
 	"github.com/google/uuid"
 )
 
-func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (string, error) {
-	claims := jwt.RegisteredClaims{
-		Issuer:    "chirpy",
-		IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
-		Subject:   userID.String(),
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	TokenVersion int32     `json:"ver"`
+	Scope        string    `json:"scope"`
+	SessionID    uuid.UUID `json:"sid"`
+}
+
+// TokenVersionFunc looks up a user's current token version. Access tokens
+// carry the version they were issued under and stop validating once the
+// user's version moves past it.
+type TokenVersionFunc func(userID uuid.UUID) (int32, error)
+
+// MakeJWT signs an access token with the keyring's active key and names the
+// key in the kid header. sessionID is the refresh token family the token was
+// issued from.
+func (k *Keyring) MakeJWT(userID, sessionID uuid.UUID, tokenVersion int32, scopes Scopes, expiresIn time.Duration) (string, error) {
+	key, err := k.signingKey()
+	if err != nil {
+		return "", err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	claims := tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    k.issuer,
+			Audience:  jwt.ClaimStrings{k.audience},
+			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+			Subject:   userID.String(),
+		},
+		TokenVersion: tokenVersion,
+		Scope:        scopes.String(),
+		SessionID:    sessionID,
+	}
 
-	return token.SignedString([]byte(tokenSecret))
-}
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = key.ID
 
-func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
+	return token.SignedString(key.PrivateKey)
+}
 
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(tokenSecret), nil
-	})
+// ValidateJWT only accepts EdDSA tokens from this keyring's issuer, meant for
+// its audience and signed by a key it holds. Anything else, including tokens
+// that name their own algorithm, is rejected.
+func (k *Keyring) ValidateJWT(tokenString string, currentVersion TokenVersionFunc) (Principal, error) {
+	claims := &tokenClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		id, _ := token.Header["kid"].(string)
+		key, ok := k.publicKey(id)
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		return key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(k.issuer),
+		jwt.WithAudience(k.audience),
+		jwt.WithExpirationRequired(),
+	)
 	if err != nil {
-		return uuid.Nil, err
+		return Principal{}, err
 	}
 
-	id, err := token.Claims.GetSubject()
+	stringToUUID, err := uuid.Parse(claims.Subject)
 	if err != nil {
-		return uuid.Nil, err
+		return Principal{}, err
 	}
 
-	stringToUUID, err := uuid.Parse(id)
+	version, err := currentVersion(stringToUUID)
 	if err != nil {
-		return uuid.Nil, err
+		return Principal{}, err
+	}
+	if claims.TokenVersion != version {
+		return Principal{}, ErrTokenRevoked
 	}
 
-	return stringToUUID, nil
+	return Principal{
+		UserID:    stringToUUID,
+		Scopes:    strings.Fields(claims.Scope),
+		SessionID: claims.SessionID,
+	}, nil
 }
 
 func GetBearerToken(headers http.Header) (string, error) {
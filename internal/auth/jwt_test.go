@@ -1,74 +1,78 @@
 package auth
 
 import (
+	"crypto/ed25519"
+	"errors"
 	"fmt"
 	"net/http"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
 func TestJWT(t *testing.T) {
 	user1 := uuid.New()
 	user2 := uuid.New()
-	tokenSecret1 := "cheese"
-	tokenSecret2 := "BillieHoliday"
+	keyring1 := testKeyring(t)
+	keyring2 := testKeyring(t)
 	expires1 := 10 * time.Second
 	expires2 := 1 * time.Minute
 	expires3 := -5 * time.Second
 
 	testList := []JWTTests{
 		{
-			TestName:   "Test 1",
-			User:       user1,
-			Secret:     tokenSecret1,
-			TestSecret: tokenSecret1,
-			Expires:    expires1,
-			Result:     true,
+			TestName:    "Test 1",
+			User:        user1,
+			Keyring:     keyring1,
+			TestKeyring: keyring1,
+			Expires:     expires1,
+			Result:      true,
 		},
 		{
-			TestName:   "Test 2",
-			User:       user2,
-			Secret:     tokenSecret2,
-			TestSecret: tokenSecret2,
-			Expires:    expires2,
-			Result:     true,
+			TestName:    "Test 2",
+			User:        user2,
+			Keyring:     keyring2,
+			TestKeyring: keyring2,
+			Expires:     expires2,
+			Result:      true,
 		},
 		{
-			TestName:   "Test 3",
-			User:       user1,
-			Secret:     tokenSecret1,
-			TestSecret: tokenSecret2,
-			Expires:    expires2,
-			Result:     false,
+			TestName:    "Test 3",
+			User:        user1,
+			Keyring:     keyring1,
+			TestKeyring: keyring2,
+			Expires:     expires2,
+			Result:      false,
 		},
 		{
-			TestName:   "Test 4",
-			User:       user2,
-			Secret:     tokenSecret2,
-			TestSecret: tokenSecret1,
-			Expires:    expires1,
-			Result:     false,
+			TestName:    "Test 4",
+			User:        user2,
+			Keyring:     keyring2,
+			TestKeyring: keyring1,
+			Expires:     expires1,
+			Result:      false,
 		},
 		{
-			TestName:   "Negative Time",
-			User:       user1,
-			Secret:     tokenSecret1,
-			TestSecret: tokenSecret1,
-			Expires:    expires3,
-			Result:     false,
+			TestName:    "Negative Time",
+			User:        user1,
+			Keyring:     keyring1,
+			TestKeyring: keyring1,
+			Expires:     expires3,
+			Result:      false,
 		},
 	}
 
 	for _, test := range testList {
 		fmt.Printf("Testing %s\n", test.TestName)
-		token, err := MakeJWT(test.User, test.Secret, test.Expires)
+		token, err := test.Keyring.MakeJWT(test.User, uuid.Nil, 0, AllScopes, test.Expires)
 		if err != nil {
 			t.Errorf("Unable to make JWT: %v", err)
 		}
 
-		validatedUser, err := ValidateJWT(token, test.TestSecret)
+		principal, err := test.TestKeyring.ValidateJWT(token, currentVersion(0))
+		validatedUser := principal.UserID
 		if !(err != nil) != test.Result {
 			t.Errorf("Validated JWT error = %v, test.Result = %v", err != nil, test.Result)
 		}
@@ -78,13 +82,59 @@ func TestJWT(t *testing.T) {
 	}
 }
 
+func testKeyring(t *testing.T) *Keyring {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("Unable to generate key: %v", err)
+	}
+
+	keyring := NewKeyring("chirpy", "chirpy-api")
+	keyring.SetKeys([]Key{key})
+	return keyring
+}
+
+func currentVersion(version int32) TokenVersionFunc {
+	return func(uuid.UUID) (int32, error) {
+		return version, nil
+	}
+}
+
+func TestJWTTokenVersion(t *testing.T) {
+	user := uuid.New()
+	session := uuid.New()
+
+	keyring := testKeyring(t)
+
+	token, err := keyring.MakeJWT(user, session, 3, AllScopes, time.Minute)
+	if err != nil {
+		t.Fatalf("Unable to make JWT: %v", err)
+	}
+
+	principal, err := keyring.ValidateJWT(token, currentVersion(3))
+	if err != nil || principal.UserID != user || principal.SessionID != session {
+		t.Errorf("principal = %v, err = %v", principal, err)
+	}
+
+	_, err = keyring.ValidateJWT(token, currentVersion(4))
+	if !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("ValidateJWT after revoking error = %v", err)
+	}
+
+	_, err = keyring.ValidateJWT(token, func(uuid.UUID) (int32, error) {
+		return 0, errors.New("user not found")
+	})
+	if err == nil {
+		t.Errorf("ValidateJWT accepted a token for a missing user")
+	}
+}
+
 type JWTTests struct {
-	TestName   string
-	User       uuid.UUID
-	Secret     string
-	TestSecret string
-	Expires    time.Duration
-	Result     bool
+	TestName    string
+	User        uuid.UUID
+	Keyring     *Keyring
+	TestKeyring *Keyring
+	Expires     time.Duration
+	Result      bool
 }
 
 func TestBearerToken(t *testing.T) {
@@ -96,3 +146,104 @@ func TestBearerToken(t *testing.T) {
 		t.Errorf("TokenString is %s and err is %v", tokenString, err)
 	}
 }
+
+func TestKeyringRotation(t *testing.T) {
+	user := uuid.New()
+
+	oldKey, _ := GenerateKey()
+	keyring := NewKeyring("chirpy", "chirpy-api")
+	keyring.SetKeys([]Key{oldKey})
+
+	oldToken, err := keyring.MakeJWT(user, uuid.Nil, 0, AllScopes, time.Minute)
+	if err != nil {
+		t.Fatalf("Unable to make JWT: %v", err)
+	}
+
+	newKey, _ := GenerateKey()
+	newKey.CreatedAt = oldKey.CreatedAt.Add(time.Second)
+	oldKey.Retired = true
+	keyring.SetKeys([]Key{oldKey, newKey})
+
+	newToken, err := keyring.MakeJWT(user, uuid.Nil, 0, AllScopes, time.Minute)
+	if err != nil {
+		t.Fatalf("Unable to make JWT: %v", err)
+	}
+
+	for name, token := range map[string]string{"old": oldToken, "new": newToken} {
+		principal, err := keyring.ValidateJWT(token, currentVersion(0))
+		if err != nil || principal.UserID != user {
+			t.Errorf("%s token: principal = %v, err = %v", name, principal, err)
+		}
+	}
+
+	jwks := keyring.JWKS()
+	if len(jwks.Keys) != 2 || jwks.Keys[0].KeyID != newKey.ID {
+		t.Errorf("JWKS = %+v", jwks)
+	}
+
+	keyring.SetKeys([]Key{newKey})
+	_, err = keyring.ValidateJWT(oldToken, currentVersion(0))
+	if !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("ValidateJWT with a dropped key error = %v", err)
+	}
+}
+
+func TestJWTClaimsPinned(t *testing.T) {
+	user := uuid.New()
+	key, _ := GenerateKey()
+
+	other := NewKeyring("someone-else", "chirpy-api")
+	other.SetKeys([]Key{key})
+	token, _ := other.MakeJWT(user, uuid.Nil, 0, AllScopes, time.Minute)
+
+	keyring := NewKeyring("chirpy", "chirpy-api")
+	keyring.SetKeys([]Key{key})
+	_, err := keyring.ValidateJWT(token, currentVersion(0))
+	if err == nil {
+		t.Errorf("ValidateJWT accepted a token from another issuer")
+	}
+
+	other = NewKeyring("chirpy", "another-api")
+	other.SetKeys([]Key{key})
+	token, _ = other.MakeJWT(user, uuid.Nil, 0, AllScopes, time.Minute)
+	_, err = keyring.ValidateJWT(token, currentVersion(0))
+	if err == nil {
+		t.Errorf("ValidateJWT accepted a token for another audience")
+	}
+
+	// An HS256 token signed with the public key as its secret is the classic
+	// algorithm confusion attack.
+	hmacToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Issuer:    "chirpy",
+		Audience:  jwt.ClaimStrings{"chirpy-api"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		Subject:   user.String(),
+	})
+	hmacToken.Header["kid"] = key.ID
+	signed, _ := hmacToken.SignedString([]byte(key.PrivateKey.Public().(ed25519.PublicKey)))
+	_, err = keyring.ValidateJWT(signed, currentVersion(0))
+	if err == nil {
+		t.Errorf("ValidateJWT accepted an HS256 token")
+	}
+}
+
+func TestJWTScopes(t *testing.T) {
+	keyring := testKeyring(t)
+
+	token, err := keyring.MakeJWT(uuid.New(), uuid.Nil, 0, Scopes{ScopeChirpsWrite}, time.Minute)
+	if err != nil {
+		t.Fatalf("Unable to make JWT: %v", err)
+	}
+
+	principal, err := keyring.ValidateJWT(token, currentVersion(0))
+	if err != nil {
+		t.Fatalf("Unable to validate JWT: %v", err)
+	}
+
+	if err := principal.Require(ScopeChirpsWrite); err != nil {
+		t.Errorf("Require(%s) error = %v", ScopeChirpsWrite, err)
+	}
+	if err := principal.Require(ScopeChirpsDelete); !errors.Is(err, ErrInsufficientScope) {
+		t.Errorf("Require(%s) error = %v", ScopeChirpsDelete, err)
+	}
+}
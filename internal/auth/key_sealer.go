@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+)
+
+var ErrUnsealKey = errors.New("signing key can't be unsealed")
+
+// KeySealer encrypts signing keys before they're stored, with AES-256-GCM
+// under a key derived from a server secret, so a copy of the database isn't
+// enough to sign tokens. Each sealed key is bound to its key ID, so sealed
+// keys can't be swapped between rows either.
+type KeySealer struct {
+	aead cipher.AEAD
+}
+
+func NewKeySealer(secret string) (*KeySealer, error) {
+	if secret == "" {
+		return nil, errors.New("signing key secret can't be empty")
+	}
+
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeySealer{
+		aead: aead,
+	}, nil
+}
+
+// Seal returns the nonce followed by the encrypted seed.
+func (s *KeySealer) Seal(keyID string, seed []byte) []byte {
+	nonce := make([]byte, s.aead.NonceSize())
+	rand.Read(nonce)
+
+	return s.aead.Seal(nonce, nonce, seed, []byte(keyID))
+}
+
+func (s *KeySealer) Open(keyID string, sealed []byte) ([]byte, error) {
+	if len(sealed) < s.aead.NonceSize() {
+		return nil, ErrUnsealKey
+	}
+
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	seed, err := s.aead.Open(nil, nonce, ciphertext, []byte(keyID))
+	if err != nil {
+		return nil, ErrUnsealKey
+	}
+	return seed, nil
+}
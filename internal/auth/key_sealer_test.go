@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestKeySealer(t *testing.T) {
+	sealer, err := NewKeySealer("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("Unable to create sealer: %v", err)
+	}
+
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("Unable to generate key: %v", err)
+	}
+	seed := key.PrivateKey.Seed()
+
+	sealed := sealer.Seal(key.ID, seed)
+	if bytes.Contains(sealed, seed) {
+		t.Errorf("sealed key contains the raw seed")
+	}
+
+	opened, err := sealer.Open(key.ID, sealed)
+	if err != nil || !bytes.Equal(opened, seed) {
+		t.Errorf("Open = %x, %v", opened, err)
+	}
+
+	_, err = sealer.Open("another-key", sealed)
+	if !errors.Is(err, ErrUnsealKey) {
+		t.Errorf("Open under another key ID error = %v", err)
+	}
+
+	other, _ := NewKeySealer("another secret")
+	_, err = other.Open(key.ID, sealed)
+	if !errors.Is(err, ErrUnsealKey) {
+		t.Errorf("Open with another secret error = %v", err)
+	}
+
+	_, err = NewKeySealer("")
+	if err == nil {
+		t.Errorf("NewKeySealer accepted an empty secret")
+	}
+}
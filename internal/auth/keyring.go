@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoSigningKey = errors.New("no active signing key")
+	ErrUnknownKey   = errors.New("token signed by an unknown key")
+)
+
+// Key is an Ed25519 key pair in the keyring. Retired keys no longer sign new
+// tokens but still verify the ones they signed until those expire.
+type Key struct {
+	ID         string
+	CreatedAt  time.Time
+	PrivateKey ed25519.PrivateKey
+	Retired    bool
+}
+
+// GenerateKey makes a new signing key with a random ID.
+func GenerateKey() (Key, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return Key{}, err
+	}
+
+	id := make([]byte, 8)
+	_, err = rand.Read(id)
+	if err != nil {
+		return Key{}, err
+	}
+
+	return Key{
+		ID:         hex.EncodeToString(id),
+		CreatedAt:  time.Now().UTC(),
+		PrivateKey: privateKey,
+	}, nil
+}
+
+// Keyring signs access tokens with its newest active key and verifies them
+// with any key it holds, so keys can be rotated without logging anyone out.
+// It is safe for concurrent use and can be swapped out while serving.
+type Keyring struct {
+	issuer   string
+	audience string
+
+	mu      sync.RWMutex
+	signing *Key
+	keys    map[string]ed25519.PublicKey
+	order   []Key
+}
+
+func NewKeyring(issuer, audience string) *Keyring {
+	return &Keyring{
+		issuer:   issuer,
+		audience: audience,
+		keys:     map[string]ed25519.PublicKey{},
+	}
+}
+
+// SetKeys replaces the keys in the ring.
+func (k *Keyring) SetKeys(keys []Key) {
+	sorted := append([]Key(nil), keys...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+	})
+
+	var signing *Key
+	verify := make(map[string]ed25519.PublicKey, len(sorted))
+	for i, key := range sorted {
+		verify[key.ID] = key.PrivateKey.Public().(ed25519.PublicKey)
+		if signing == nil && !key.Retired {
+			signing = &sorted[i]
+		}
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.signing = signing
+	k.keys = verify
+	k.order = sorted
+}
+
+func (k *Keyring) signingKey() (*Key, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if k.signing == nil {
+		return nil, ErrNoSigningKey
+	}
+	return k.signing, nil
+}
+
+func (k *Keyring) publicKey(id string) (ed25519.PublicKey, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, ok := k.keys[id]
+	return key, ok
+}
+
+// JWK is the public half of a key in JSON Web Key form.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS lists every verification key, newest first, for services that check
+// access tokens without holding any private key.
+func (k *Keyring) JWKS() JWKSet {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	set := JWKSet{Keys: make([]JWK, 0, len(k.order))}
+	for _, key := range k.order {
+		set.Keys = append(set.Keys, JWK{
+			KeyType:   "OKP",
+			Curve:     "Ed25519",
+			X:         base64.RawURLEncoding.EncodeToString(key.PrivateKey.Public().(ed25519.PublicKey)),
+			KeyID:     key.ID,
+			Algorithm: "EdDSA",
+			Use:       "sig",
+		})
+	}
+	return set
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MinPasswordLength = 12
+	// MaxPasswordBytes is as much as bcrypt reads; anything past it would be
+	// silently ignored.
+	MaxPasswordBytes = 72
+)
+
+var (
+	ErrPasswordTooShort  = errors.New("password must be at least 12 characters")
+	ErrPasswordTooLong   = errors.New("password must be at most 72 bytes")
+	ErrPasswordTooCommon = errors.New("password is too common")
+	ErrPasswordHasEmail  = errors.New("password can't contain the email address")
+)
+
+// commonPasswords are the ones long enough to pass the length check that
+// still turn up at the top of every breach list.
+var commonPasswords = map[string]bool{
+	"123456789012":     true,
+	"1234567890123":    true,
+	"12345678901234":   true,
+	"aaaaaaaaaaaa":     true,
+	"password1234":     true,
+	"password12345":    true,
+	"password123456":   true,
+	"passwordpassword": true,
+	"qwertyuiopas":     true,
+	"qwerty123456":     true,
+	"iloveyou1234":     true,
+	"letmein12345":     true,
+	"welcome12345":     true,
+	"administrator":    true,
+	"chirpychirpy":     true,
+}
+
+// CheckPasswordStrength applies the policy for new passwords. It favours
+// length over character-class rules, and rejects passwords that are common
+// or built from the account's own email.
+func CheckPasswordStrength(password, email string) error {
+	if utf8.RuneCountInString(password) < MinPasswordLength {
+		return ErrPasswordTooShort
+	}
+	if len(password) > MaxPasswordBytes {
+		return ErrPasswordTooLong
+	}
+
+	lowered := strings.ToLower(password)
+	if commonPasswords[lowered] {
+		return ErrPasswordTooCommon
+	}
+
+	local, _, _ := strings.Cut(strings.ToLower(email), "@")
+	if len(local) >= 4 && strings.Contains(lowered, local) {
+		return ErrPasswordHasEmail
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckPasswordStrength(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		email    string
+		want     error
+	}{
+		{"long enough", "correct horse battery", "snake@example.com", nil},
+		{"too short", "keptyouwait", "snake@example.com", ErrPasswordTooShort},
+		{"short in bytes, long in runes", "ééééééééééé", "snake@example.com", ErrPasswordTooShort},
+		{"too long", strings.Repeat("ab", 37), "snake@example.com", ErrPasswordTooLong},
+		{"common", "Password1234", "snake@example.com", ErrPasswordTooCommon},
+		{"contains email", "solidsnake-1987", "SolidSnake@example.com", ErrPasswordHasEmail},
+		{"short local part ignored", "big boss forever", "big@example.com", nil},
+	}
+
+	for _, test := range tests {
+		err := CheckPasswordStrength(test.password, test.email)
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s: CheckPasswordStrength() = %v, want %v", test.name, err, test.want)
+		}
+	}
+}
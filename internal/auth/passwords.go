@@ -1,9 +1,21 @@
 package auth
 
 import (
+	"sync"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
+// dummyHash is compared against when there's no account to check, so that a
+// login for an unknown email takes as long as one with a wrong password.
+var dummyHash = sync.OnceValue(func() string {
+	hash, err := HashPassword("not a real password")
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
 func HashPassword(password string) (string, error) {
 	encrypted, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -16,3 +28,12 @@ func HashPassword(password string) (string, error) {
 func CheckPasswordHash(password, hash string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 }
+
+// CheckNoPassword does the same work as CheckPasswordHash and always fails.
+func CheckNoPassword(password string) error {
+	err := CheckPasswordHash(password, dummyHash())
+	if err == nil {
+		return bcrypt.ErrMismatchedHashAndPassword
+	}
+	return err
+}
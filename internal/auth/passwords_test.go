@@ -43,6 +43,14 @@ func TestPasswords(t *testing.T) {
 	}
 }
 
+func TestCheckNoPassword(t *testing.T) {
+	for _, password := range []string{"", "snakeeater", "not a real password"} {
+		if err := CheckNoPassword(password); err == nil {
+			t.Errorf("CheckNoPassword(%q) succeeded", password)
+		}
+	}
+}
+
 type Tests struct {
 	Name     string
 	Password string
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// CreateRecoveryCodes makes n single-use 2FA recovery codes of 80 random bits
+// each, written as four dash-separated groups so they are easy to copy down.
+func CreateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	for range n {
+		raw := make([]byte, 10)
+		_, err := rand.Read(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		encoded := strings.ToLower(recoveryEncoding.EncodeToString(raw))
+		codes = append(codes, encoded[0:4]+"-"+encoded[4:8]+"-"+encoded[8:12]+"-"+encoded[12:16])
+	}
+
+	return codes, nil
+}
+
+// HashRecoveryCode hashes a recovery code the way it is stored, ignoring case,
+// spaces and dashes in what the user typed.
+func HashRecoveryCode(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+
+	return HashToken(normalized)
+}
@@ -2,12 +2,40 @@ package auth
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"strings"
 )
 
+// PersonalAccessTokenPrefix marks personal access tokens so they can be told
+// apart from JWTs in an Authorization header, and spotted by secret scanners.
+const PersonalAccessTokenPrefix = "chirpy_pat_"
+
 func CreateRefreshToken() (string, error) {
 	key := make([]byte, 32)
 	rand.Read(key)
 
 	return hex.EncodeToString(key), nil
 }
+
+func CreatePersonalAccessToken() (string, error) {
+	token, err := CreateRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	return PersonalAccessTokenPrefix + token, nil
+}
+
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix)
+}
+
+// HashToken returns the digest a refresh or personal access token is stored
+// and looked up by, so a copy of the database can't be used to sign in.
+// Tokens are 256 bits of randomness, which makes a plain SHA-256 enough;
+// there is nothing to brute-force.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
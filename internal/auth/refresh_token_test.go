@@ -0,0 +1,25 @@
+package auth
+
+import "testing"
+
+func TestHashToken(t *testing.T) {
+	token1, _ := CreateRefreshToken()
+	token2, _ := CreateRefreshToken()
+
+	if HashToken(token1) != HashToken(token1) {
+		t.Errorf("hashing the same token twice gave different digests")
+	}
+	if HashToken(token1) == HashToken(token2) {
+		t.Errorf("different tokens gave the same digest")
+	}
+	if HashToken(token1) == token1 {
+		t.Errorf("digest is the raw token")
+	}
+
+	// Must agree with encode(sha256(convert_to(token, 'UTF8')), 'hex'), which
+	// the migration used to convert existing tokens.
+	expected := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if digest := HashToken("hello"); digest != expected {
+		t.Errorf("HashToken(hello) = %s", digest)
+	}
+}
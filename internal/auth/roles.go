@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+type Role string
+
+// Roles are ordered: each one can do everything the roles before it can.
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+var roleOrder = []Role{RoleUser, RoleModerator, RoleAdmin}
+
+var ErrInsufficientRole = errors.New("user lacks the required role")
+
+// ParseRole accepts one of the known role names.
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if !slices.Contains(roleOrder, role) {
+		return "", fmt.Errorf("unknown role %q", s)
+	}
+	return role, nil
+}
+
+// AtLeast reports whether r includes everything min is allowed to do. An
+// unknown role includes nothing.
+func (r Role) AtLeast(min Role) bool {
+	have := slices.Index(roleOrder, r)
+	return have >= 0 && have >= slices.Index(roleOrder, min)
+}
+
+// Require returns ErrInsufficientRole unless r is at least min.
+func (r Role) Require(min Role) error {
+	if !r.AtLeast(min) {
+		return fmt.Errorf("%w: %s", ErrInsufficientRole, min)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package auth
+
+import "testing"
+
+func TestRoleAtLeast(t *testing.T) {
+	cases := []struct {
+		role Role
+		min  Role
+		want bool
+	}{
+		{RoleAdmin, RoleModerator, true},
+		{RoleAdmin, RoleAdmin, true},
+		{RoleModerator, RoleModerator, true},
+		{RoleModerator, RoleAdmin, false},
+		{RoleUser, RoleModerator, false},
+		{Role("root"), RoleUser, false},
+		{Role(""), RoleUser, false},
+	}
+
+	for _, c := range cases {
+		if got := c.role.AtLeast(c.min); got != c.want {
+			t.Errorf("Role(%q).AtLeast(%q) = %v, want %v", c.role, c.min, got, c.want)
+		}
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	role, err := ParseRole("moderator")
+	if err != nil || role != RoleModerator {
+		t.Errorf("ParseRole(moderator) = %q, %v", role, err)
+	}
+
+	_, err = ParseRole("superuser")
+	if err == nil {
+		t.Errorf("ParseRole accepted an unknown role")
+	}
+}
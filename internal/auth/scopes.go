@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ScopeChirpsWrite  = "chirps:write"
+	ScopeChirpsDelete = "chirps:delete"
+	ScopeProfileWrite = "profile:write"
+	// ScopeAccount covers sessions and personal access tokens. Only tokens
+	// from a password login carry it, so a leaked personal access token can't
+	// be used to mint more of them.
+	ScopeAccount = "account"
+)
+
+// AllScopes is what an access token from logging in is granted.
+var AllScopes = Scopes{ScopeChirpsWrite, ScopeChirpsDelete, ScopeProfileWrite, ScopeAccount}
+
+// GrantableScopes are the scopes a personal access token may ask for.
+var GrantableScopes = Scopes{ScopeChirpsWrite, ScopeChirpsDelete, ScopeProfileWrite}
+
+var ErrInsufficientScope = errors.New("token lacks the required scope")
+
+type Scopes []string
+
+func (s Scopes) Has(scope string) bool {
+	return slices.Contains(s, scope)
+}
+
+// String is the space-separated form used in the scope claim.
+func (s Scopes) String() string {
+	return strings.Join(s, " ")
+}
+
+// ParseScopes checks requested scopes against the allowed list, dropping
+// duplicates.
+func ParseScopes(requested []string, allowed Scopes) (Scopes, error) {
+	scopes := Scopes{}
+	for _, scope := range requested {
+		if !allowed.Has(scope) {
+			return nil, fmt.Errorf("unknown scope %q", scope)
+		}
+		if !scopes.Has(scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+// Principal is whoever a request's bearer token speaks for, and what it may
+// do on their behalf.
+type Principal struct {
+	UserID uuid.UUID
+	Scopes Scopes
+	// SessionID is the session an access token belongs to, or uuid.Nil for
+	// a personal access token.
+	SessionID uuid.UUID
+}
+
+// Require returns ErrInsufficientScope unless the principal holds scope.
+func (p Principal) Require(scope string) error {
+	if !p.Scopes.Has(scope) {
+		return fmt.Errorf("%w: %s", ErrInsufficientScope, scope)
+	}
+	return nil
+}
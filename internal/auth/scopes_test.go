@@ -0,0 +1,39 @@
+package auth
+
+import "testing"
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := ParseScopes([]string{ScopeChirpsWrite, ScopeProfileWrite, ScopeChirpsWrite}, GrantableScopes)
+	if err != nil {
+		t.Fatalf("Unable to parse scopes: %v", err)
+	}
+	if scopes.String() != "chirps:write profile:write" {
+		t.Errorf("scopes = %q", scopes.String())
+	}
+
+	_, err = ParseScopes([]string{ScopeAccount}, GrantableScopes)
+	if err == nil {
+		t.Errorf("ParseScopes granted %s to a personal access token", ScopeAccount)
+	}
+
+	_, err = ParseScopes([]string{"chirps:everything"}, GrantableScopes)
+	if err == nil {
+		t.Errorf("ParseScopes accepted an unknown scope")
+	}
+}
+
+func TestPersonalAccessToken(t *testing.T) {
+	token, err := CreatePersonalAccessToken()
+	if err != nil {
+		t.Fatalf("Unable to create token: %v", err)
+	}
+
+	if !IsPersonalAccessToken(token) {
+		t.Errorf("IsPersonalAccessToken(%s) = false", token)
+	}
+
+	refreshToken, _ := CreateRefreshToken()
+	if IsPersonalAccessToken(refreshToken) {
+		t.Errorf("IsPersonalAccessToken(%s) = true", refreshToken)
+	}
+}
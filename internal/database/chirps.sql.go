@@ -7,29 +7,33 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
 )
 
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, parent_id, published_at)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3,
+    NOW()
 )
-RETURNING id, created_at, updated_at, body, user_id
+RETURNING id, created_at, updated_at, body, user_id, edited_at, parent_id, reply_count, deleted_at, like_count, repost_count, search_vector, publish_at, published_at, hidden_at
 `
 
 type CreateChirpParams struct {
-	Body   string
-	UserID uuid.UUID
+	Body     string
+	UserID   uuid.UUID
+	ParentID uuid.NullUUID
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID, arg.ParentID)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -37,6 +41,58 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.EditedAt,
+		&i.ParentID,
+		&i.ReplyCount,
+		&i.DeletedAt,
+		&i.LikeCount,
+		&i.RepostCount,
+		&i.SearchVector,
+		&i.PublishAt,
+		&i.PublishedAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const createUnpublishedChirp = `-- name: CreateUnpublishedChirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, publish_at)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3
+)
+RETURNING id, created_at, updated_at, body, user_id, edited_at, parent_id, reply_count, deleted_at, like_count, repost_count, search_vector, publish_at, published_at, hidden_at
+`
+
+type CreateUnpublishedChirpParams struct {
+	Body      string
+	UserID    uuid.UUID
+	PublishAt sql.NullTime
+}
+
+func (q *Queries) CreateUnpublishedChirp(ctx context.Context, arg CreateUnpublishedChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, createUnpublishedChirp, arg.Body, arg.UserID, arg.PublishAt)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.EditedAt,
+		&i.ParentID,
+		&i.ReplyCount,
+		&i.DeletedAt,
+		&i.LikeCount,
+		&i.RepostCount,
+		&i.SearchVector,
+		&i.PublishAt,
+		&i.PublishedAt,
+		&i.HiddenAt,
 	)
 	return i, err
 }
@@ -10,7 +10,7 @@ import (
 )
 
 const lookUpUser = `-- name: LookUpUser :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, token_version, totp_secret, totp_enabled_at, totp_last_step, email_verified_at, handle, display_name, bio, avatar_media_id, delete_after, role FROM users
 WHERE email = $1
 `
 
@@ -24,6 +24,17 @@ func (q *Queries) LookUpUser(ctx context.Context, email string) (User, error) {
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.TokenVersion,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.EmailVerifiedAt,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarMediaID,
+		&i.DeleteAfter,
+		&i.Role,
 	)
 	return i, err
 }
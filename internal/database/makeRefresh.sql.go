@@ -7,37 +7,53 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
 )
 
 const makeRefreshToken = `-- name: MakeRefreshToken :one
-INSERT INTO refresh_tokens (token, created_at, updated_at, user_id, expires_at)
+INSERT INTO refresh_tokens (token_hash, created_at, updated_at, user_id, expires_at, family_id, parent_token_hash, user_agent, ip_address, last_used_at)
 VALUES (
     $1,
     NOW(),
     NOW(),
     $2,
-    NOW() + INTERVAL '60 day'
+    NOW() + INTERVAL '60 day',
+    $3,
+    $4,
+    $5,
+    $6,
+    NOW()
 )
-RETURNING token, created_at, updated_at, user_id, expires_at, revoked_at
+RETURNING token_hash, created_at, updated_at, user_id, expires_at, revoked_at, family_id, parent_token_hash, rotated_at, user_agent, ip_address, last_used_at
 `
 
 type MakeRefreshTokenParams struct {
-	Token  string
-	UserID uuid.UUID
+	TokenHash       string
+	UserID          uuid.UUID
+	FamilyID        uuid.UUID
+	ParentTokenHash sql.NullString
+	UserAgent       string
+	IpAddress       string
 }
 
 func (q *Queries) MakeRefreshToken(ctx context.Context, arg MakeRefreshTokenParams) (RefreshToken, error) {
-	row := q.db.QueryRowContext(ctx, makeRefreshToken, arg.Token, arg.UserID)
+	row := q.db.QueryRowContext(ctx, makeRefreshToken, arg.TokenHash, arg.UserID, arg.FamilyID, arg.ParentTokenHash, arg.UserAgent, arg.IpAddress)
 	var i RefreshToken
 	err := row.Scan(
-		&i.Token,
+		&i.TokenHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.ExpiresAt,
 		&i.RevokedAt,
+		&i.FamilyID,
+		&i.ParentTokenHash,
+		&i.RotatedAt,
+		&i.UserAgent,
+		&i.IpAddress,
+		&i.LastUsedAt,
 	)
 	return i, err
 }
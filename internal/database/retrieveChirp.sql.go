@@ -12,7 +12,7 @@ import (
 )
 
 const retrieveChirp = `-- name: RetrieveChirp :one
-SELECT id, created_at, updated_at, body, user_id FROM chirps
+SELECT id, created_at, updated_at, body, user_id, edited_at, parent_id, reply_count, deleted_at, like_count, repost_count, search_vector, publish_at, published_at, hidden_at FROM chirps
 WHERE id = $1
 `
 
@@ -25,6 +25,16 @@ func (q *Queries) RetrieveChirp(ctx context.Context, id uuid.UUID) (Chirp, error
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.EditedAt,
+		&i.ParentID,
+		&i.ReplyCount,
+		&i.DeletedAt,
+		&i.LikeCount,
+		&i.RepostCount,
+		&i.SearchVector,
+		&i.PublishAt,
+		&i.PublishedAt,
+		&i.HiddenAt,
 	)
 	return i, err
 }
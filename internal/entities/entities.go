@@ -0,0 +1,90 @@
+package entities
+
+import (
+	"strings"
+	"unicode"
+)
+
+type Kind string
+
+const (
+	Hashtag Kind = "hashtag"
+	Mention Kind = "mention"
+)
+
+// Entity is a #hashtag or @mention found in a chirp body. Start and End are
+// rune offsets into the body and include the leading sigil.
+type Entity struct {
+	Kind  Kind
+	Text  string
+	Start int
+	End   int
+}
+
+// Extract finds every hashtag and mention in body. A sigil only starts an
+// entity at the beginning of the body or after a character that can't be
+// part of one, so email addresses and "a#b" are left alone.
+func Extract(body string) []Entity {
+	runes := []rune(body)
+	found := []Entity{}
+
+	for i := 0; i < len(runes); i++ {
+		var kind Kind
+		switch runes[i] {
+		case '#':
+			kind = Hashtag
+		case '@':
+			kind = Mention
+		default:
+			continue
+		}
+
+		if i > 0 && (isNameRune(runes[i-1]) || runes[i-1] == '#' || runes[i-1] == '@') {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isNameRune(runes[end]) {
+			end++
+		}
+
+		text := string(runes[i+1 : end])
+		if text == "" || (kind == Hashtag && !strings.ContainsFunc(text, unicode.IsLetter)) {
+			continue
+		}
+
+		found = append(found, Entity{
+			Kind:  kind,
+			Text:  text,
+			Start: i,
+			End:   end,
+		})
+		i = end - 1
+	}
+
+	return found
+}
+
+// Names returns the distinct, lower-cased texts of the entities of one kind.
+func Names(found []Entity, kind Kind) []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, entity := range found {
+		if entity.Kind != kind {
+			continue
+		}
+
+		name := strings.ToLower(entity.Text)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func isNameRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
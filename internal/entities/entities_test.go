@@ -0,0 +1,82 @@
+package entities
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	testList := []struct {
+		Name   string
+		Body   string
+		Result []Entity
+	}{
+		{
+			Name:   "no entities",
+			Body:   "just a plain chirp",
+			Result: []Entity{},
+		},
+		{
+			Name: "hashtag and mention",
+			Body: "hey @Saul check #BreakingBad",
+			Result: []Entity{
+				{Kind: Mention, Text: "Saul", Start: 4, End: 9},
+				{Kind: Hashtag, Text: "BreakingBad", Start: 16, End: 28},
+			},
+		},
+		{
+			Name: "punctuation ends an entity",
+			Body: "#go, #rust!",
+			Result: []Entity{
+				{Kind: Hashtag, Text: "go", Start: 0, End: 3},
+				{Kind: Hashtag, Text: "rust", Start: 5, End: 10},
+			},
+		},
+		{
+			Name:   "email is not a mention",
+			Body:   "mail walt@example.com",
+			Result: []Entity{},
+		},
+		{
+			Name: "sigil inside an entity ends it",
+			Body: "#a#b @c@d",
+			Result: []Entity{
+				{Kind: Hashtag, Text: "a", Start: 0, End: 2},
+				{Kind: Mention, Text: "c", Start: 5, End: 7},
+			},
+		},
+		{
+			Name:   "numeric hashtag is ignored",
+			Body:   "we're #1",
+			Result: []Entity{},
+		},
+		{
+			Name: "offsets count runes",
+			Body: "héllo #café",
+			Result: []Entity{
+				{Kind: Hashtag, Text: "café", Start: 6, End: 11},
+			},
+		},
+	}
+
+	for _, test := range testList {
+		result := Extract(test.Body)
+		if !reflect.DeepEqual(result, test.Result) {
+			t.Errorf("%s: Extract(%q) = %v, want %v", test.Name, test.Body, result, test.Result)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	found := Extract("#Go #go @Jesse #chirpy @jesse")
+
+	hashtags := Names(found, Hashtag)
+	if !reflect.DeepEqual(hashtags, []string{"go", "chirpy"}) {
+		t.Errorf("hashtags = %v", hashtags)
+	}
+
+	mentions := Names(found, Mention)
+	if !reflect.DeepEqual(mentions, []string{"jesse"}) {
+		t.Errorf("mentions = %v", mentions)
+	}
+}
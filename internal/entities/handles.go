@@ -0,0 +1,51 @@
+package entities
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	MinHandleLength = 3
+	MaxHandleLength = 30
+)
+
+var (
+	ErrHandleLength   = errors.New("handle must be between 3 and 30 characters")
+	ErrHandleChars    = errors.New("handle can only contain letters, digits and underscores")
+	ErrHandleReserved = errors.New("handle is reserved")
+)
+
+// reservedHandles would be confusing as someone's name, or clash with paths
+// under /api/users.
+var reservedHandles = map[string]bool{
+	"admin":     true,
+	"api":       true,
+	"chirpy":    true,
+	"me":        true,
+	"moderator": true,
+	"root":      true,
+	"support":   true,
+	"system":    true,
+}
+
+// ValidateHandle checks that handle can be claimed. Handles are ASCII so
+// that look-alike letters can't impersonate someone, and every valid handle
+// is matched whole by a mention in a chirp body.
+func ValidateHandle(handle string) error {
+	if len(handle) < MinHandleLength || len(handle) > MaxHandleLength {
+		return ErrHandleLength
+	}
+
+	for _, r := range handle {
+		if !(r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+			return ErrHandleChars
+		}
+	}
+
+	if reservedHandles[strings.ToLower(handle)] {
+		return ErrHandleReserved
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateHandle(t *testing.T) {
+	testList := []struct {
+		Handle string
+		Result error
+	}{
+		{Handle: "Saul_Goodman", Result: nil},
+		{Handle: "abc", Result: nil},
+		{Handle: "ab", Result: ErrHandleLength},
+		{Handle: "a_handle_that_is_far_too_long_x", Result: ErrHandleLength},
+		{Handle: "walt.white", Result: ErrHandleChars},
+		{Handle: "jessé", Result: ErrHandleChars},
+		{Handle: "Admin", Result: ErrHandleReserved},
+	}
+
+	for _, test := range testList {
+		err := ValidateHandle(test.Handle)
+		if !errors.Is(err, test.Result) {
+			t.Errorf("ValidateHandle(%q) = %v, want %v", test.Handle, err, test.Result)
+		}
+
+		if test.Result == nil {
+			found := Extract("hi @" + test.Handle + "!")
+			if len(found) != 1 || found[0].Text != test.Handle {
+				t.Errorf("mention of %q extracted as %v", test.Handle, found)
+			}
+		}
+	}
+}
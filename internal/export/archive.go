@@ -0,0 +1,59 @@
+// Package export writes a user's data out as a zip archive.
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Archive is a zip file being written. Entries are added in order and the
+// archive is only valid once Close returns.
+type Archive struct {
+	zw      *zip.Writer
+	created time.Time
+}
+
+func NewArchive(w io.Writer, created time.Time) *Archive {
+	return &Archive{
+		zw:      zip.NewWriter(w),
+		created: created,
+	}
+}
+
+// AddJSON writes v as indented JSON under name.
+func (a *Archive) AddJSON(name string, v any) error {
+	w, err := a.create(name, a.created, zip.Deflate)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// AddFile copies r into the archive under name. Media is already compressed,
+// so it's stored as is.
+func (a *Archive) AddFile(name string, r io.Reader, modified time.Time) error {
+	w, err := a.create(name, modified, zip.Store)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, r)
+	return err
+}
+
+func (a *Archive) Close() error {
+	return a.zw.Close()
+}
+
+func (a *Archive) create(name string, modified time.Time, method uint16) (io.Writer, error) {
+	return a.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   method,
+		Modified: modified,
+	})
+}
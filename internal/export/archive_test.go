@@ -0,0 +1,75 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArchive(t *testing.T) {
+	created := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	archive := NewArchive(&buf, created)
+
+	err := archive.AddJSON("profile.json", map[string]string{"handle": "saul"})
+	if err != nil {
+		t.Fatalf("AddJSON error = %v", err)
+	}
+
+	err = archive.AddFile("media/a.png", strings.NewReader("not really a png"), created.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("AddFile error = %v", err)
+	}
+
+	err = archive.Close()
+	if err != nil {
+		t.Fatalf("Close error = %v", err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("archive can't be read: %v", err)
+	}
+
+	if len(reader.File) != 2 {
+		t.Fatalf("archive has %d files, want 2", len(reader.File))
+	}
+
+	profile := map[string]string{}
+	err = json.Unmarshal(readFile(t, reader.File[0]), &profile)
+	if err != nil || profile["handle"] != "saul" {
+		t.Errorf("profile.json = %v, err = %v", profile, err)
+	}
+
+	media := reader.File[1]
+	if media.Name != "media/a.png" || media.Method != zip.Store {
+		t.Errorf("media entry = %s with method %d", media.Name, media.Method)
+	}
+	if !media.Modified.Equal(created.Add(-time.Hour)) {
+		t.Errorf("media modified = %v", media.Modified)
+	}
+	if got := string(readFile(t, media)); got != "not really a png" {
+		t.Errorf("media contents = %q", got)
+	}
+}
+
+func readFile(t *testing.T, file *zip.File) []byte {
+	t.Helper()
+
+	rc, err := file.Open()
+	if err != nil {
+		t.Fatalf("Unable to open %s: %v", file.Name, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("Unable to read %s: %v", file.Name, err)
+	}
+	return data
+}
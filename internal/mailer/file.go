@@ -0,0 +1,65 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// FileMailer writes each message to its own .eml file instead of sending it,
+// for development.
+type FileMailer struct {
+	dir   string
+	from  string
+	count atomic.Int64
+}
+
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileMailer{
+		dir:  dir,
+		from: from,
+	}, nil
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+	data, err := render(m.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%04d.eml", now.UTC().Format("20060102T150405.000000000"), m.count.Add(1))
+	return os.WriteFile(filepath.Join(m.dir, name), data, 0o600)
+}
+
+// LogMailer prints messages to a logger instead of sending them.
+type LogMailer struct {
+	logger *log.Logger
+	from   string
+}
+
+func NewLogMailer(logger *log.Logger, from string) *LogMailer {
+	return &LogMailer{
+		logger: logger,
+		from:   from,
+	}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	data, err := render(m.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	m.logger.Printf("Mail:\n%s", data)
+	return nil
+}
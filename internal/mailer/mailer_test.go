@@ -0,0 +1,173 @@
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testMessage = Message{
+	To:      "walt@example.com",
+	Subject: "Reset your password",
+	Body:    "Follow this link:\nhttps://chirpy.example/reset?token=abc\n",
+}
+
+func TestRender(t *testing.T) {
+	data, err := render("chirpy@example.com", testMessage, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Unable to render message: %v", err)
+	}
+
+	expected := "From: chirpy@example.com\r\n" +
+		"To: walt@example.com\r\n" +
+		"Subject: Reset your password\r\n" +
+		"Date: Fri, 02 Jan 2026 03:04:05 +0000\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" +
+		"Follow this link:\r\nhttps://chirpy.example/reset?token=abc\r\n"
+	if string(data) != expected {
+		t.Errorf("render =\n%q\nwant\n%q", data, expected)
+	}
+
+	_, err = render("chirpy@example.com", Message{
+		To:      "walt@example.com\r\nBcc: everyone@example.com",
+		Subject: "hi",
+	}, time.Now())
+	if !errors.Is(err, ErrInvalidHeader) {
+		t.Errorf("render with header injection error = %v", err)
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	mailer, err := NewFileMailer(dir, "chirpy@example.com")
+	if err != nil {
+		t.Fatalf("Unable to create mailer: %v", err)
+	}
+
+	for range 2 {
+		err = mailer.Send(context.Background(), testMessage)
+		if err != nil {
+			t.Fatalf("Unable to send: %v", err)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 2 {
+		t.Fatalf("found %d messages", len(files))
+	}
+
+	data, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(data), "To: walt@example.com\r\n") {
+		t.Errorf("message = %q", data)
+	}
+}
+
+func TestLogMailer(t *testing.T) {
+	var buf bytes.Buffer
+	mailer := NewLogMailer(log.New(&buf, "", 0), "chirpy@example.com")
+
+	err := mailer.Send(context.Background(), testMessage)
+	if err != nil {
+		t.Fatalf("Unable to send: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "Subject: Reset your password") {
+		t.Errorf("log = %q", buf.String())
+	}
+}
+
+// fakeSMTP accepts a single message without authentication and passes what
+// it received back on the channel.
+func fakeSMTP(t *testing.T) (string, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		reply("220 localhost ESMTP")
+		var transcript strings.Builder
+		inData := false
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if inData {
+				if line == ".\r\n" {
+					inData = false
+					received <- transcript.String()
+					reply("250 OK")
+					continue
+				}
+				transcript.WriteString(line)
+				continue
+			}
+
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(command, "MAIL FROM"), strings.HasPrefix(command, "RCPT TO"):
+				transcript.WriteString(line)
+				reply("250 OK")
+			case command == "DATA":
+				inData = true
+				reply("354 Go ahead")
+			case command == "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), received
+}
+
+func TestSMTPMailer(t *testing.T) {
+	addr, received := fakeSMTP(t)
+
+	mailer, err := NewSMTPMailer(addr, "chirpy@example.com", "", "")
+	if err != nil {
+		t.Fatalf("Unable to create mailer: %v", err)
+	}
+
+	err = mailer.Send(context.Background(), testMessage)
+	if err != nil {
+		t.Fatalf("Unable to send: %v", err)
+	}
+
+	select {
+	case transcript := <-received:
+		for _, want := range []string{"RCPT TO:<walt@example.com>", "Subject: Reset your password", "https://chirpy.example/reset?token=abc"} {
+			if !strings.Contains(transcript, want) {
+				t.Errorf("transcript is missing %q:\n%s", want, transcript)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("fake SMTP server never received the message")
+	}
+}
@@ -0,0 +1,69 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+)
+
+const maxDimension = 8192
+
+var ErrUnsupportedType = errors.New("unsupported media type")
+
+// Processed is an upload that has been sniffed, checked and stripped of
+// metadata, ready to be stored.
+type Processed struct {
+	Data        []byte
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+}
+
+// Process works out what an upload really is from its contents rather than
+// whatever the client claimed, refuses anything that isn't a well-formed
+// JPEG, PNG or GIF of sensible dimensions, and strips EXIF and other metadata
+// that can carry location data.
+func Process(data []byte) (Processed, error) {
+	contentType := http.DetectContentType(data)
+
+	var extension string
+	var stripped []byte
+	var err error
+	switch contentType {
+	case "image/jpeg":
+		extension = ".jpg"
+		stripped, err = stripJPEG(data)
+	case "image/png":
+		extension = ".png"
+		stripped, err = stripPNG(data)
+	case "image/gif":
+		extension = ".gif"
+		stripped = data
+	default:
+		return Processed{}, ErrUnsupportedType
+	}
+	if err != nil {
+		return Processed{}, err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(stripped))
+	if err != nil {
+		return Processed{}, err
+	}
+	if config.Width > maxDimension || config.Height > maxDimension {
+		return Processed{}, errors.New("image dimensions are too large")
+	}
+
+	return Processed{
+		Data:        stripped,
+		ContentType: contentType,
+		Extension:   extension,
+		Width:       config.Width,
+		Height:      config.Height,
+	}, nil
+}
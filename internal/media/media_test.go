@@ -0,0 +1,96 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	img.Set(1, 1, color.RGBA{R: 255, A: 255})
+	return img
+}
+
+func TestProcessJPEGStripsExif(t *testing.T) {
+	var encoded bytes.Buffer
+	err := jpeg.Encode(&encoded, testImage(), nil)
+	if err != nil {
+		t.Fatalf("Unable to encode JPEG: %v", err)
+	}
+
+	exif := append([]byte("Exif\x00\x00"), []byte("GPS 51.5074 N 0.1278 W")...)
+	app1 := []byte{0xFF, 0xE1, 0, byte(len(exif) + 2)}
+	app1 = append(app1, exif...)
+
+	original := encoded.Bytes()
+	withExif := append([]byte{}, original[:2]...)
+	withExif = append(withExif, app1...)
+	withExif = append(withExif, original[2:]...)
+
+	processed, err := Process(withExif)
+	if err != nil {
+		t.Fatalf("Unable to process JPEG: %v", err)
+	}
+	if processed.ContentType != "image/jpeg" || processed.Extension != ".jpg" {
+		t.Errorf("ContentType = %s, Extension = %s", processed.ContentType, processed.Extension)
+	}
+	if bytes.Contains(processed.Data, []byte("GPS")) {
+		t.Errorf("EXIF data was not stripped")
+	}
+	if !bytes.Equal(processed.Data, original) {
+		t.Errorf("Stripped JPEG differs from the original encoding")
+	}
+	if processed.Width != 4 || processed.Height != 3 {
+		t.Errorf("Width = %d, Height = %d", processed.Width, processed.Height)
+	}
+}
+
+func TestProcessPNGStripsText(t *testing.T) {
+	var encoded bytes.Buffer
+	err := png.Encode(&encoded, testImage())
+	if err != nil {
+		t.Fatalf("Unable to encode PNG: %v", err)
+	}
+
+	// A tEXt chunk with a deliberately wrong CRC is fine: it gets dropped.
+	text := []byte("Comment\x00secret location")
+	chunk := []byte{0, 0, 0, byte(len(text))}
+	chunk = append(chunk, []byte("tEXt")...)
+	chunk = append(chunk, text...)
+	chunk = append(chunk, 0, 0, 0, 0)
+
+	original := encoded.Bytes()
+	withText := append([]byte{}, original[:33]...)
+	withText = append(withText, chunk...)
+	withText = append(withText, original[33:]...)
+
+	processed, err := Process(withText)
+	if err != nil {
+		t.Fatalf("Unable to process PNG: %v", err)
+	}
+	if bytes.Contains(processed.Data, []byte("secret")) {
+		t.Errorf("tEXt chunk was not stripped")
+	}
+	if !bytes.Equal(processed.Data, original) {
+		t.Errorf("Stripped PNG differs from the original encoding")
+	}
+}
+
+func TestProcessRejectsUnsupported(t *testing.T) {
+	testList := [][]byte{
+		[]byte("just some text"),
+		[]byte("<html><script>alert(1)</script></html>"),
+		{0xFF, 0xD8, 0xFF},
+	}
+
+	for _, test := range testList {
+		_, err := Process(test)
+		if err == nil {
+			t.Errorf("Process(%q) returned no error", test)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package moderation
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// LengthFilter rejects bodies longer than Max characters.
+type LengthFilter struct {
+	Max int
+}
+
+func (f LengthFilter) Check(body string) Result {
+	if utf8.RuneCountInString(body) > f.Max {
+		return Result{
+			Body:   body,
+			Action: Reject,
+			Reason: fmt.Sprintf("Chirp is longer than %d characters", f.Max),
+		}
+	}
+
+	return Result{Body: body}
+}
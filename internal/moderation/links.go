@@ -0,0 +1,37 @@
+package moderation
+
+import (
+	"regexp"
+	"strings"
+)
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://)?((?:[a-z0-9-]+\.)+[a-z]{2,})(?:[/:?#]\S*)?`)
+
+// LinkBlocklist rejects bodies linking to a blocked domain or any of its
+// subdomains.
+type LinkBlocklist struct {
+	Action  Action
+	Domains []string
+}
+
+func (f LinkBlocklist) Check(body string) Result {
+	for _, match := range linkPattern.FindAllStringSubmatch(body, -1) {
+		host := strings.ToLower(match[1])
+		for _, domain := range f.Domains {
+			domain = strings.ToLower(domain)
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return Result{
+					Body:   strings.ReplaceAll(body, match[0], maskedWord),
+					Action: f.Action,
+					Reason: "Chirp links to a blocked domain",
+				}
+			}
+		}
+	}
+
+	return Result{Body: body}
+}
+
+func countLinks(body string) int {
+	return len(linkPattern.FindAllString(body, -1))
+}
@@ -0,0 +1,78 @@
+package moderation
+
+import "strings"
+
+// Action is what a filter does with a body that trips it.
+type Action int
+
+const (
+	// Mask rewrites the offending parts of the body and lets it through.
+	Mask Action = iota
+	// Flag lets the body through but queues it for human review.
+	Flag
+	// Reject refuses the body outright.
+	Reject
+)
+
+// Result is one filter's opinion of a body. Body is the possibly-masked text
+// that the next filter in the pipeline sees. Reason is empty when the filter
+// had nothing to say.
+type Result struct {
+	Body   string
+	Action Action
+	Reason string
+}
+
+type Filter interface {
+	Check(body string) Result
+}
+
+// Verdict is the combined outcome of running a body through a Pipeline.
+type Verdict struct {
+	Body     string
+	Rejected bool
+	Flagged  bool
+	Reasons  []string
+}
+
+func (v Verdict) Reason() string {
+	return strings.Join(v.Reasons, "; ")
+}
+
+type Pipeline struct {
+	filters []Filter
+}
+
+func NewPipeline(filters ...Filter) *Pipeline {
+	return &Pipeline{
+		filters: filters,
+	}
+}
+
+// Moderate runs body through every filter in order. Masks carry forward to
+// later filters, flags accumulate, and the first rejection stops the run.
+func (p *Pipeline) Moderate(body string) Verdict {
+	verdict := Verdict{
+		Body: body,
+	}
+
+	for _, filter := range p.filters {
+		result := filter.Check(verdict.Body)
+		if result.Reason == "" {
+			continue
+		}
+
+		verdict.Reasons = append(verdict.Reasons, result.Reason)
+		switch result.Action {
+		case Mask:
+			verdict.Body = result.Body
+		case Flag:
+			verdict.Flagged = true
+		case Reject:
+			verdict.Rejected = true
+			return verdict
+		}
+	}
+
+	return verdict
+}
@@ -0,0 +1,130 @@
+package moderation
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestWordList(t *testing.T) {
+	list := NewWordList(Mask, []string{"kerfuffle", "sharbert", "fornax"})
+
+	testList := []struct {
+		Name   string
+		Body   string
+		Result string
+	}{
+		{
+			Name:   "clean body",
+			Body:   "This is a clean chirp",
+			Result: "This is a clean chirp",
+		},
+		{
+			Name:   "plain word",
+			Body:   "I had something interesting for breakfast kerfuffle",
+			Result: "I had something interesting for breakfast ****",
+		},
+		{
+			Name:   "punctuation and case",
+			Body:   "Kerfuffle! What a Sharbert, honestly.",
+			Result: "****! What a ****, honestly.",
+		},
+		{
+			Name:   "substring is left alone",
+			Body:   "fornaxes are not fornax",
+			Result: "fornaxes are not ****",
+		},
+		{
+			Name:   "quoted in apostrophes",
+			Body:   "what a 'kerfuffle' that was",
+			Result: "what a '****' that was",
+		},
+		{
+			Name:   "possessive",
+			Body:   "the kerfuffle's end and Sharbert'S too",
+			Result: "the ****'s end and ****'S too",
+		},
+		{
+			Name:   "contraction is left alone",
+			Body:   "fornax'd isn't a word",
+			Result: "fornax'd isn't a word",
+		},
+	}
+
+	for _, test := range testList {
+		result := list.Check(test.Body)
+		if result.Body != test.Result {
+			t.Errorf("%s: body = %q, want %q", test.Name, result.Body, test.Result)
+		}
+		if (result.Reason != "") != (test.Body != test.Result) {
+			t.Errorf("%s: reason = %q", test.Name, result.Reason)
+		}
+	}
+
+	list.SetWords([]string{"breakfast"})
+	result := list.Check("kerfuffle for breakfast")
+	if result.Body != "kerfuffle for ****" {
+		t.Errorf("after SetWords body = %q", result.Body)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	pipeline := NewPipeline(
+		LengthFilter{Max: 140},
+		NewWordList(Mask, []string{"kerfuffle"}),
+		RegexFilter{Action: Flag, Patterns: []*regexp.Regexp{regexp.MustCompile(`(?i)buy now`)}},
+		LinkBlocklist{Action: Reject, Domains: []string{"spam.example"}},
+		SpamHeuristic{Action: Flag, MaxLinks: 2, MaxTags: 5, MaxRepeatedRun: 10},
+	)
+
+	testList := []struct {
+		Name     string
+		Body     string
+		Result   string
+		Rejected bool
+		Flagged  bool
+	}{
+		{
+			Name:   "clean body",
+			Body:   "hello world",
+			Result: "hello world",
+		},
+		{
+			Name:   "length counts runes",
+			Body:   strings.Repeat("é ", 70),
+			Result: strings.Repeat("é ", 70),
+		},
+		{
+			Name:     "too long",
+			Body:     strings.Repeat("a ", 71),
+			Rejected: true,
+		},
+		{
+			Name:    "masked and flagged",
+			Body:    "Kerfuffle! Buy now",
+			Result:  "****! Buy now",
+			Flagged: true,
+		},
+		{
+			Name:     "blocked link",
+			Body:     "see https://www.spam.example/deal",
+			Rejected: true,
+		},
+		{
+			Name:    "spam heuristics",
+			Body:    "soooooooooooooooo good",
+			Result:  "soooooooooooooooo good",
+			Flagged: true,
+		},
+	}
+
+	for _, test := range testList {
+		verdict := pipeline.Moderate(test.Body)
+		if verdict.Rejected != test.Rejected || verdict.Flagged != test.Flagged {
+			t.Errorf("%s: rejected = %v, flagged = %v, reasons = %v", test.Name, verdict.Rejected, verdict.Flagged, verdict.Reasons)
+		}
+		if !test.Rejected && verdict.Body != test.Result {
+			t.Errorf("%s: body = %q, want %q", test.Name, verdict.Body, test.Result)
+		}
+	}
+}
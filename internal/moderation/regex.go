@@ -0,0 +1,64 @@
+package moderation
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// RegexFilter trips on any body matching one of its patterns. Masking
+// replaces each match with asterisks.
+type RegexFilter struct {
+	Action   Action
+	Patterns []*regexp.Regexp
+}
+
+func (f RegexFilter) Check(body string) Result {
+	matched := false
+	cleaned := body
+	for _, pattern := range f.Patterns {
+		if !pattern.MatchString(cleaned) {
+			continue
+		}
+		matched = true
+		cleaned = pattern.ReplaceAllString(cleaned, maskedWord)
+	}
+
+	if !matched {
+		return Result{Body: body}
+	}
+
+	return Result{
+		Body:   cleaned,
+		Action: f.Action,
+		Reason: "Chirp matches a blocked pattern",
+	}
+}
+
+// LoadPatternsFile compiles one regular expression per line, skipping blank
+// lines and lines starting with #.
+func LoadPatternsFile(path string) ([]*regexp.Regexp, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	patterns := []*regexp.Regexp{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		pattern, err := regexp.Compile(line)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, scanner.Err()
+}
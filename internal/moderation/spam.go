@@ -0,0 +1,76 @@
+package moderation
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SpamHeuristic catches the usual signs of spam: lots of links or tags,
+// long runs of the same character and shouting.
+type SpamHeuristic struct {
+	Action         Action
+	MaxLinks       int
+	MaxTags        int
+	MaxRepeatedRun int
+}
+
+func (f SpamHeuristic) Check(body string) Result {
+	if f.MaxLinks > 0 && countLinks(body) > f.MaxLinks {
+		return f.trip(body, "Chirp contains too many links")
+	}
+
+	if f.MaxTags > 0 && strings.Count(body, "#")+strings.Count(body, "@") > f.MaxTags {
+		return f.trip(body, "Chirp contains too many hashtags or mentions")
+	}
+
+	if f.MaxRepeatedRun > 0 && longestRun(body) > f.MaxRepeatedRun {
+		return f.trip(body, "Chirp repeats the same character too often")
+	}
+
+	if isShouting(body) {
+		return f.trip(body, "Chirp is written in all capitals")
+	}
+
+	return Result{Body: body}
+}
+
+func (f SpamHeuristic) trip(body, reason string) Result {
+	return Result{
+		Body:   body,
+		Action: f.Action,
+		Reason: reason,
+	}
+}
+
+func longestRun(body string) int {
+	longest, run := 0, 0
+	var previous rune
+	for i, r := range body {
+		if i > 0 && r == previous && !unicode.IsSpace(r) {
+			run++
+		} else {
+			run = 1
+		}
+		previous = r
+		longest = max(longest, run)
+	}
+
+	return longest
+}
+
+// isShouting reports whether a body with a fair amount of text has no
+// lower-case letters at all.
+func isShouting(body string) bool {
+	letters, upper := 0, 0
+	for _, r := range body {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.IsUpper(r) {
+			upper++
+		}
+	}
+
+	return letters >= 20 && upper == letters
+}
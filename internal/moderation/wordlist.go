@@ -0,0 +1,128 @@
+package moderation
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const maskedWord = "****"
+
+// WordList matches whole words against a list that can be swapped out while
+// the server is running. Matching ignores case and surrounding punctuation, so
+// "Kerfuffle!" is caught as well as "kerfuffle".
+type WordList struct {
+	action Action
+	mu     sync.RWMutex
+	words  map[string]bool
+}
+
+func NewWordList(action Action, words []string) *WordList {
+	list := &WordList{
+		action: action,
+	}
+	list.SetWords(words)
+
+	return list
+}
+
+func (l *WordList) SetWords(words []string) {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word != "" {
+			set[word] = true
+		}
+	}
+
+	l.mu.Lock()
+	l.words = set
+	l.mu.Unlock()
+}
+
+func (l *WordList) Check(body string) Result {
+	l.mu.RLock()
+	words := l.words
+	l.mu.RUnlock()
+
+	var cleaned strings.Builder
+	matched := false
+	runes := []rune(body)
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			cleaned.WriteRune(runes[i])
+			i++
+			continue
+		}
+
+		end := i
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+
+		word := string(runes[i:end])
+		prefix, core, suffix := splitWord(word)
+		if words[strings.ToLower(core)] {
+			matched = true
+			cleaned.WriteString(prefix + maskedWord + suffix)
+		} else {
+			cleaned.WriteString(word)
+		}
+		i = end
+	}
+
+	if !matched {
+		return Result{Body: body}
+	}
+
+	return Result{
+		Body:   cleaned.String(),
+		Action: l.action,
+		Reason: "Chirp contains a blocked word",
+	}
+}
+
+// LoadWordsFile reads one word per line, skipping blank lines and lines
+// starting with #.
+func LoadWordsFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	words := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+
+	return words, scanner.Err()
+}
+
+// splitWord separates the apostrophes wrapped around a word, and a possessive
+// 's, from the word itself, so "'kerfuffle'" and "kerfuffle's" are looked up
+// as "kerfuffle".
+func splitWord(word string) (prefix, core, suffix string) {
+	core = strings.TrimLeft(word, "'")
+	prefix = word[:len(word)-len(core)]
+
+	trimmed := strings.TrimRight(core, "'")
+	lower := strings.ToLower(trimmed)
+	if strings.HasSuffix(lower, "'s") {
+		trimmed = strings.TrimRight(trimmed[:len(trimmed)-2], "'")
+	}
+	suffix = core[len(trimmed):]
+
+	return prefix, trimmed, suffix
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\''
+}
@@ -0,0 +1,65 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cursor marks the last row of a page in a keyset ordered by (created_at, id).
+// Relevance-ordered search results put the row's rank in front of that key.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+	Rank      *float32
+}
+
+func (c Cursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "," + c.ID.String()
+	if c.Rank != nil {
+		raw += "," + strconv.FormatFloat(float64(*c.Rank), 'g', -1, 32)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, errors.New("malformed cursor")
+	}
+
+	parts := strings.Split(string(raw), ",")
+	if len(parts) != 2 && len(parts) != 3 {
+		return Cursor{}, errors.New("malformed cursor")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return Cursor{}, errors.New("malformed cursor")
+	}
+
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return Cursor{}, errors.New("malformed cursor")
+	}
+
+	cursor := Cursor{
+		CreatedAt: createdAt,
+		ID:        id,
+	}
+
+	if len(parts) == 3 {
+		rank, err := strconv.ParseFloat(parts[2], 32)
+		if err != nil {
+			return Cursor{}, errors.New("malformed cursor")
+		}
+		rank32 := float32(rank)
+		cursor.Rank = &rank32
+	}
+
+	return cursor, nil
+}
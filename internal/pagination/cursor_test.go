@@ -0,0 +1,56 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := Cursor{
+		CreatedAt: time.Date(2025, 4, 3, 12, 30, 15, 123456000, time.UTC),
+		ID:        uuid.New(),
+	}
+
+	decoded, err := DecodeCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("Unable to decode cursor: %v", err)
+	}
+	if !decoded.CreatedAt.Equal(cursor.CreatedAt) || decoded.ID != cursor.ID || decoded.Rank != nil {
+		t.Errorf("decoded = %v, cursor = %v", decoded, cursor)
+	}
+}
+
+func TestRankedCursorRoundTrip(t *testing.T) {
+	rank := float32(0.0607927)
+	cursor := Cursor{
+		CreatedAt: time.Date(2025, 4, 3, 12, 30, 15, 0, time.UTC),
+		ID:        uuid.New(),
+		Rank:      &rank,
+	}
+
+	decoded, err := DecodeCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("Unable to decode cursor: %v", err)
+	}
+	if decoded.Rank == nil || *decoded.Rank != rank {
+		t.Errorf("decoded.Rank = %v, rank = %v", decoded.Rank, rank)
+	}
+}
+
+func TestDecodeCursorMalformed(t *testing.T) {
+	testList := []string{
+		"",
+		"not base64!",
+		Cursor{}.Encode()[:10],
+		"MjAyNS0wNC0wM1QxMjozMDoxNVo",
+	}
+
+	for _, test := range testList {
+		_, err := DecodeCursor(test)
+		if err == nil {
+			t.Errorf("DecodeCursor(%q) returned no error", test)
+		}
+	}
+}
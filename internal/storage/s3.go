@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// S3Store talks to any S3-compatible service using path-style URLs, which is
+// what MinIO and most self-hosted stand-ins expect.
+type S3Store struct {
+	endpoint *url.URL
+	bucket   string
+	creds    credentials
+	client   *http.Client
+	now      func() time.Time
+}
+
+func NewS3Store(endpoint, bucket, region, accessKey, secretKey string) (*S3Store, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("s3 endpoint %q needs a scheme and host", endpoint)
+	}
+
+	return &S3Store{
+		endpoint: parsed,
+		bucket:   bucket,
+		creds: credentials{
+			AccessKey: accessKey,
+			SecretKey: secretKey,
+			Region:    region,
+		},
+		client: &http.Client{Timeout: 30 * time.Second},
+		now:    time.Now,
+	}, nil
+}
+
+func (s *S3Store) objectURL(key string) string {
+	objectURL := *s.endpoint
+	objectURL.Path = "/" + s.bucket + "/" + key
+	return objectURL.String()
+}
+
+func (s *S3Store) do(ctx context.Context, method, key string, body io.ReadSeeker, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	// The signature covers the payload's hash, so the body is read once to
+	// hash it and again to send it rather than being held in memory.
+	payloadHash := emptyPayloadHash
+	if body != nil {
+		hash := sha256.New()
+		size, err := io.Copy(hash, body)
+		if err != nil {
+			return nil, err
+		}
+		_, err = body.Seek(0, io.SeekStart)
+		if err != nil {
+			return nil, err
+		}
+
+		payloadHash = hex.EncodeToString(hash.Sum(nil))
+		req.ContentLength = size
+		req.Body = http.NoBody
+		if size > 0 {
+			req.Body = io.NopCloser(body)
+		}
+	}
+	signV4(req, s.creds, payloadHash, s.now())
+
+	return s.client.Do(req)
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, body io.ReadSeeker, contentType string) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, key, body, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("s3 put %s: unexpected status %s", key, resp.Status)
+	}
+	return nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	if !validKey(key) {
+		return nil, Object{}, ErrNotFound
+	}
+
+	resp, err := s.do(ctx, http.MethodGet, key, nil, "")
+	if err != nil {
+		return nil, Object{}, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, Object{}, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, Object{}, fmt.Errorf("s3 get %s: unexpected status %s", key, resp.Status)
+	}
+
+	size, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+
+	return resp.Body, Object{
+		ContentType: resp.Header.Get("Content-Type"),
+		Size:        size,
+		ModTime:     modTime,
+	}, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("s3 delete %s: unexpected status %s", key, resp.Status)
+	}
+	return nil
+}
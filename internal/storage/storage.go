@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+type Object struct {
+	ContentType string
+	Size        int64
+	ModTime     time.Time
+}
+
+// Store holds uploaded media. Keys are flat names such as "<uuid>.jpg". Put
+// takes a ReadSeeker so large objects can come straight from a file.
+type Store interface {
+	Put(ctx context.Context, key string, body io.ReadSeeker, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, Object, error)
+	Delete(ctx context.Context, key string) error
+}
+
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+func validKey(key string) bool {
+	return len(key) <= 255 && keyPattern.MatchString(key)
+}
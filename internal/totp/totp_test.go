@@ -0,0 +1,93 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The SHA-1 test secret from RFC 6238, appendix B.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// Appendix B lists eight-digit codes; six-digit codes are their last six.
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, test := range tests {
+		code, err := Code(rfcSecret, Step(time.Unix(test.unix, 0)))
+		if err != nil {
+			t.Fatalf("Unable to generate code: %v", err)
+		}
+		if code != test.code {
+			t.Errorf("Code at %d = %s, want %s", test.unix, code, test.code)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	step, ok := Verify(rfcSecret, "050471", now)
+	if !ok || step != Step(now) {
+		t.Errorf("Verify(current code) = %d, %v", step, ok)
+	}
+
+	_, ok = Verify(rfcSecret, "050471", now.Add(Period))
+	if !ok {
+		t.Errorf("Verify rejected a code one step old")
+	}
+
+	_, ok = Verify(rfcSecret, "050471", now.Add(3*Period))
+	if ok {
+		t.Errorf("Verify accepted a code three steps old")
+	}
+
+	_, ok = Verify(rfcSecret, "123456", now)
+	if ok {
+		t.Errorf("Verify accepted a wrong code")
+	}
+
+	_, ok = Verify(rfcSecret, "50471", now)
+	if ok {
+		t.Errorf("Verify accepted a short code")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("Unable to generate secret: %v", err)
+	}
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	code, err := Code(secret, Step(now))
+	if err != nil {
+		t.Fatalf("Unable to generate code: %v", err)
+	}
+
+	_, ok := Verify(secret, code, now)
+	if !ok {
+		t.Errorf("Verify rejected a freshly generated code")
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Chirpy", "walt@example.com", "JBSWY3DPEHPK3PXP")
+	if !strings.HasPrefix(uri, "otpauth://totp/Chirpy:walt@example.com?") {
+		t.Errorf("URI = %s", uri)
+	}
+	if !strings.Contains(uri, "secret=JBSWY3DPEHPK3PXP") || !strings.Contains(uri, "issuer=Chirpy") {
+		t.Errorf("URI = %s", uri)
+	}
+}
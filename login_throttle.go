@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+)
+
+var (
+	// accountBackoff slows guessing against one account, from any address.
+	accountBackoff = auth.Backoff{Free: 5, Base: time.Second, Max: 15 * time.Minute}
+	// ipBackoff is looser, since one address can be shared by many people,
+	// but still stops a single client spraying guesses across accounts.
+	ipBackoff = auth.Backoff{Free: 20, Base: time.Second, Max: time.Hour}
+
+	// Password reset requests all count, not just failed ones, since each
+	// one sends an email. A few a day per address are plenty.
+	resetAccountBackoff = auth.Backoff{Free: 3, Base: time.Minute, Max: time.Hour}
+	resetIPBackoff      = auth.Backoff{Free: 10, Base: time.Minute, Max: time.Hour}
+)
+
+// loginThrottle holds the throttle keys for one login attempt. The account
+// key is built from the email as given, so it works the same whether or not
+// the account exists.
+type loginThrottle struct {
+	account        string
+	ip             string
+	accountBackoff auth.Backoff
+	ipBackoff      auth.Backoff
+}
+
+func newLoginThrottle(req *http.Request, email string) loginThrottle {
+	return loginThrottle{
+		account:        "account:" + normalizeThrottleEmail(email),
+		ip:             "ip:" + clientIP(req),
+		accountBackoff: accountBackoff,
+		ipBackoff:      ipBackoff,
+	}
+}
+
+// newPasswordResetThrottle limits reset emails per address and per client,
+// under keys of its own so reset requests never lock anyone out of logging in.
+func newPasswordResetThrottle(req *http.Request, email string) loginThrottle {
+	return loginThrottle{
+		account:        "reset:account:" + normalizeThrottleEmail(email),
+		ip:             "reset:ip:" + clientIP(req),
+		accountBackoff: resetAccountBackoff,
+		ipBackoff:      resetIPBackoff,
+	}
+}
+
+func normalizeThrottleEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// begin counts the attempt as a failure against both keys before the
+// password is even checked, so a burst of parallel guesses can't all get in
+// before the first failure is written: each attempt holds the throttle rows
+// until it has set any lock it earns, and the next one sees it. It returns
+// when an attempt may next be made if the keys are locked now, in which case
+// nothing is counted, or the zero time if this attempt may go ahead.
+func (t loginThrottle) begin(ctx context.Context, conn *sql.DB, db *database.Queries) (time.Time, error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer tx.Rollback()
+	qtx := db.WithTx(tx)
+
+	now := time.Now().UTC()
+	lockedUntil := time.Time{}
+	locks := []database.LockLoginParams{}
+	for _, key := range []struct {
+		name    string
+		backoff auth.Backoff
+	}{
+		{t.account, t.accountBackoff},
+		{t.ip, t.ipBackoff},
+	} {
+		attempt, err := qtx.RecordLoginFailure(ctx, key.name)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if attempt.LockedUntil.Valid && attempt.LockedUntil.Time.After(now) {
+			lockedUntil = later(lockedUntil, attempt.LockedUntil.Time)
+			continue
+		}
+
+		delay := key.backoff.Delay(int(attempt.Failures))
+		if delay > 0 {
+			locks = append(locks, database.LockLoginParams{
+				LockedUntil: now.Add(delay),
+				Key:         key.name,
+			})
+		}
+	}
+
+	// A locked out attempt rolls back, so it isn't counted and doesn't push
+	// the lock any further out.
+	if !lockedUntil.IsZero() {
+		return lockedUntil, nil
+	}
+
+	for _, lock := range locks {
+		err = qtx.LockLogin(ctx, lock)
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	return time.Time{}, tx.Commit()
+}
+
+// recordSuccess forgets the account's failures and takes this attempt back
+// off the address. The address keeps its earlier failures, so knowing one
+// password doesn't buy more guesses at other accounts.
+func (t loginThrottle) recordSuccess(ctx context.Context, db *database.Queries) error {
+	err := db.ClearLoginFailures(ctx, t.account)
+	if err != nil {
+		return err
+	}
+	return t.forgiveAddress(ctx, db)
+}
+
+// forgiveAddress takes this attempt back off the address only, for a correct
+// password that still has a second factor to pass before the account's
+// failures are forgotten.
+func (t loginThrottle) forgiveAddress(ctx context.Context, db *database.Queries) error {
+	return db.ForgiveLoginFailure(ctx, t.ip)
+}
+
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+func respondWithLoginLocked(w http.ResponseWriter, until time.Time) {
+	respondWithThrottled(w, until, "Too many login attempts, try again later")
+}
+
+func respondWithThrottled(w http.ResponseWriter, until time.Time, msg string) {
+	retryAfter := int(time.Until(until).Seconds()) + 1
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	respondWithError(w, http.StatusTooManyRequests, msg, nil)
+}
+
+// pruneLoginThrottles drops failure counts that have aged out, so the table
+// only holds recent attempts.
+func (cfg *apiConfig) pruneLoginThrottles(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		err := cfg.db.DeleteStaleLoginThrottles(context.Background())
+		if err != nil {
+			log.Printf("Unable to prune login throttles: %v", err)
+		}
+	}
+}
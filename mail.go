@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+	"github.com/willthefoollearn/chirpy/internal/mailer"
+)
+
+const (
+	purposePasswordReset = "password_reset"
+	purposeVerifyEmail   = "verify_email"
+
+	passwordResetLifetime     = time.Hour
+	emailVerificationLifetime = 48 * time.Hour
+
+	mailTimeout = 30 * time.Second
+)
+
+// setupMailer picks how email goes out. MAILER=smtp sends through SMTP_ADDR,
+// logging in with SMTP_USERNAME and SMTP_PASSWORD if set; MAILER=file writes
+// each message to MAIL_DIR, which defaults to the user's cache directory and
+// must not be one /app/ serves, since messages carry reset links; anything
+// else just logs messages. MAIL_FROM is the sender and APP_URL the base of
+// links in messages.
+func (cfg *apiConfig) setupMailer() error {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "Chirpy <no-reply@localhost>"
+	}
+
+	cfg.appURL = strings.TrimSuffix(os.Getenv("APP_URL"), "/")
+	if cfg.appURL == "" {
+		cfg.appURL = "http://localhost:8080/app"
+	}
+
+	switch os.Getenv("MAILER") {
+	case "smtp":
+		smtpMailer, err := mailer.NewSMTPMailer(
+			os.Getenv("SMTP_ADDR"),
+			from,
+			os.Getenv("SMTP_USERNAME"),
+			os.Getenv("SMTP_PASSWORD"),
+		)
+		if err != nil {
+			return err
+		}
+		cfg.mailer = smtpMailer
+	case "file":
+		dir, err := privateDir("MAIL_DIR", "mail")
+		if err != nil {
+			return err
+		}
+		fileMailer, err := mailer.NewFileMailer(dir, from)
+		if err != nil {
+			return err
+		}
+		cfg.mailer = fileMailer
+	default:
+		cfg.mailer = mailer.NewLogMailer(log.Default(), from)
+	}
+
+	return nil
+}
+
+// sendMail sends in the background. Callers have already committed whatever
+// the message is about, and a slow mail server shouldn't hold up the request
+// or reveal through timing whether an address has an account.
+func (cfg *apiConfig) sendMail(msg mailer.Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+		defer cancel()
+
+		err := cfg.mailer.Send(ctx, msg)
+		if err != nil {
+			log.Printf("Unable to send %q to %s: %v", msg.Subject, msg.To, err)
+		}
+	}()
+}
+
+// issueEmailToken makes a single-use token for a link sent by email. Only its
+// digest is stored, and any earlier token for the same purpose stops working.
+func issueEmailToken(ctx context.Context, db *database.Queries, userID uuid.UUID, purpose, email string, lifetime time.Duration) (string, error) {
+	err := db.ExpireEmailTokens(ctx, database.ExpireEmailTokensParams{
+		UserID:  userID,
+		Purpose: purpose,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	token, err := auth.CreateRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = db.CreateEmailToken(ctx, database.CreateEmailTokenParams{
+		TokenHash: auth.HashToken(token),
+		UserID:    userID,
+		Purpose:   purpose,
+		Email:     email,
+		ExpiresAt: time.Now().UTC().Add(lifetime),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// sendVerificationEmail asks the owner of email to confirm it. Until they do,
+// the user's address doesn't change.
+func (cfg *apiConfig) sendVerificationEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	token, err := issueEmailToken(ctx, cfg.db, userID, purposeVerifyEmail, email, emailVerificationLifetime)
+	if err != nil {
+		return err
+	}
+
+	cfg.sendVerificationMail(email, token)
+	return nil
+}
+
+// sendVerificationMail mails a token already issued to confirm email.
+func (cfg *apiConfig) sendVerificationMail(email, token string) {
+	cfg.sendMail(mailer.Message{
+		To:      email,
+		Subject: "Confirm your email address for Chirpy",
+		Body: fmt.Sprintf(
+			"Confirm this address by following the link below:\n\n%s/verify-email?token=%s\n\nThe link expires in 48 hours. If you didn't ask for this, you can ignore this email.\n",
+			cfg.appURL, token,
+		),
+	})
+}
+
+func (cfg *apiConfig) sendPasswordResetEmail(ctx context.Context, user database.User) error {
+	token, err := issueEmailToken(ctx, cfg.db, user.ID, purposePasswordReset, user.Email, passwordResetLifetime)
+	if err != nil {
+		return err
+	}
+
+	cfg.sendMail(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Chirpy password",
+		Body: fmt.Sprintf(
+			"Someone asked to reset the password for this account. To choose a new one, follow the link below:\n\n%s/reset-password?token=%s\n\nThe link expires in an hour. If you didn't ask for this, you can ignore this email.\n",
+			cfg.appURL, token,
+		),
+	})
+	return nil
+}
@@ -1,17 +1,26 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	"github.com/willthefoollearn/chirpy/internal/auth"
 	"github.com/willthefoollearn/chirpy/internal/database"
+	"github.com/willthefoollearn/chirpy/internal/mailer"
+	"github.com/willthefoollearn/chirpy/internal/moderation"
+	"github.com/willthefoollearn/chirpy/internal/storage"
 )
 
 func main() {
@@ -24,33 +33,146 @@ func main() {
 	defer db.Close()
 
 	dbQueries := database.New(db)
+
+	if len(os.Args) == 3 && os.Args[1] == "bootstrap-admin" {
+		err = bootstrapAdmin(context.Background(), dbQueries, os.Args[2])
+		if err != nil {
+			log.Fatalf("unable to bootstrap admin: %v", err)
+		}
+		return
+	}
+
 	mux := http.NewServeMux()
 	cfg := &apiConfig{
 		fileserverHits: atomic.Int32{},
+		conn:           db,
 		db:             dbQueries,
 		platform:       os.Getenv("PLATFORM"),
-		secret:         os.Getenv("SECRET"),
 		polka_key:      os.Getenv("POLKA_KEY"),
 	}
 
+	err = cfg.setupSigningKeys(context.Background())
+	if err != nil {
+		log.Fatalf("unable to set up signing keys: %v", err)
+	}
+	go cfg.watchSigningKeys(time.Minute)
+
+	err = cfg.setupModeration()
+	if err != nil {
+		log.Fatalf("unable to set up moderation: %v", err)
+	}
+	err = cfg.reloadModerationWords(context.Background())
+	if err != nil {
+		log.Printf("Unable to load moderation words: %v", err)
+	}
+	go cfg.watchModerationWords(time.Minute)
+	go cfg.runScheduler(15 * time.Second)
+	go cfg.pruneLoginThrottles(time.Hour)
+	go cfg.liftExpiredSuspensions(time.Minute)
+
+	err = cfg.setupMediaStorage()
+	if err != nil {
+		log.Fatalf("unable to set up media storage: %v", err)
+	}
+
+	err = cfg.setupExportStorage()
+	if err != nil {
+		log.Fatalf("unable to set up export storage: %v", err)
+	}
+	go cfg.runExports(30 * time.Second)
+	go cfg.runAccountReaper(time.Hour)
+
+	err = cfg.setupMailer()
+	if err != nil {
+		log.Fatalf("unable to set up mailer: %v", err)
+	}
+
 	server := &http.Server{}
 
 	server.Addr = ":8080"
 	server.Handler = mux
 
-	mux.Handle("/app/", cfg.middlewareMetricsInc(http.StripPrefix("/app", http.FileServer(http.Dir(".")))))
+	mux.Handle("/app/", cfg.appHandler())
+	mux.Handle("/media/", middlewareMediaCache(http.StripPrefix("/media", mediaServer{store: cfg.media})))
 	mux.HandleFunc("GET /api/healthz", handlerReadiness)
-	mux.HandleFunc("GET /admin/metrics", cfg.handlerNumOfRequests)
-	mux.HandleFunc("POST /admin/reset", cfg.handlerResetHits)
+	mux.HandleFunc("GET /.well-known/jwks.json", cfg.handlerJWKS)
+	if cfg.platform == "dev" {
+		mux.Handle("POST /admin/reset", cfg.requireRole(auth.RoleAdmin, cfg.handlerResetHits))
+	}
+	mux.Handle("GET /admin/metrics", cfg.requireRole(auth.RoleModerator, cfg.handlerNumOfRequests))
+	mux.Handle("POST /admin/keys/rotate", cfg.requireRole(auth.RoleAdmin, cfg.handlerRotateSigningKeys))
+	mux.Handle("PUT /admin/users/{userID}/role", cfg.requireRole(auth.RoleAdmin, cfg.handlerSetUserRole))
+	mux.Handle("GET /admin/reports", cfg.requireRole(auth.RoleModerator, cfg.handlerListReports))
+	mux.Handle("POST /admin/reports/{reportID}/assign", cfg.requireRole(auth.RoleModerator, cfg.handlerAssignReport))
+	mux.Handle("POST /admin/reports/{reportID}/resolve", cfg.requireRole(auth.RoleModerator, cfg.handlerResolveReport))
+	mux.Handle("POST /admin/chirps/{chirpID}/hide", cfg.requireRole(auth.RoleModerator, cfg.handlerHideChirp))
+	mux.Handle("DELETE /admin/chirps/{chirpID}/hide", cfg.requireRole(auth.RoleModerator, cfg.handlerUnhideChirp))
+	mux.Handle("POST /admin/users/{userID}/suspend", cfg.requireRole(auth.RoleAdmin, cfg.handlerSuspendUser))
+	mux.Handle("DELETE /admin/users/{userID}/suspend", cfg.requireRole(auth.RoleAdmin, cfg.handlerUnsuspendUser))
+	mux.Handle("GET /admin/suspensions", cfg.requireRole(auth.RoleAdmin, cfg.handlerListSuspensions))
+	mux.Handle("GET /admin/audit", cfg.requireRole(auth.RoleAdmin, cfg.handlerListAuditEvents))
+	mux.Handle("GET /admin/moderation/words", cfg.requireRole(auth.RoleModerator, cfg.handlerListModerationWords))
+	mux.Handle("POST /admin/moderation/words", cfg.requireRole(auth.RoleModerator, cfg.handlerAddModerationWord))
+	mux.Handle("DELETE /admin/moderation/words/{word}", cfg.requireRole(auth.RoleModerator, cfg.handlerDeleteModerationWord))
+	mux.Handle("GET /admin/moderation/flags", cfg.requireRole(auth.RoleModerator, cfg.handlerListModerationFlags))
+	mux.Handle("POST /admin/moderation/flags/{flagID}/resolve", cfg.requireRole(auth.RoleModerator, cfg.handlerResolveModerationFlag))
 	mux.HandleFunc("POST /api/users", cfg.handlerCreateUser)
+	mux.HandleFunc("POST /api/password-reset/request", cfg.handlerRequestPasswordReset)
+	mux.HandleFunc("POST /api/password-reset/confirm", cfg.handlerConfirmPasswordReset)
+	mux.HandleFunc("POST /api/email-verification/confirm", cfg.handlerConfirmEmail)
+	mux.HandleFunc("POST /api/email-verification/resend", cfg.handlerResendVerification)
 	mux.HandleFunc("PUT /api/users", cfg.handlerUserUpdate)
+	mux.HandleFunc("PATCH /api/users", cfg.handlerUserUpdate)
+	mux.HandleFunc("GET /api/users/{user}", cfg.handlerGetProfile)
+	mux.HandleFunc("DELETE /api/users/me", cfg.handlerDeleteAccount)
+	mux.HandleFunc("POST /api/users/me/restore", cfg.handlerRestoreAccount)
+	mux.HandleFunc("GET /api/users/me/export", cfg.handlerExportData)
+	mux.HandleFunc("POST /api/users/{userID}/follow", cfg.handlerFollow)
+	mux.HandleFunc("DELETE /api/users/{userID}/follow", cfg.handlerUnfollow)
+	mux.HandleFunc("GET /api/users/{userID}/followers", cfg.handlerListFollowers)
+	mux.HandleFunc("GET /api/users/{userID}/following", cfg.handlerListFollowing)
+	mux.HandleFunc("GET /api/users/{userID}/mentions", cfg.handlerUserMentions)
+	mux.HandleFunc("POST /api/users/{userID}/report", cfg.handlerReportUser)
+	mux.HandleFunc("POST /api/users/{userID}/block", cfg.handlerBlock)
+	mux.HandleFunc("DELETE /api/users/{userID}/block", cfg.handlerUnblock)
+	mux.HandleFunc("POST /api/users/{userID}/mute", cfg.handlerMute)
+	mux.HandleFunc("DELETE /api/users/{userID}/mute", cfg.handlerUnmute)
+	mux.HandleFunc("GET /api/users/me/blocks", cfg.handlerListBlocks)
+	mux.HandleFunc("GET /api/users/me/mutes", cfg.handlerListMutes)
+	mux.HandleFunc("GET /api/timeline", cfg.handlerTimeline)
+	mux.HandleFunc("GET /api/hashtags/{tag}/chirps", cfg.handlerHashtagChirps)
+	mux.HandleFunc("GET /api/search/chirps", cfg.handlerSearchChirps)
+	mux.HandleFunc("POST /api/media", cfg.handlerUploadMedia)
 	mux.HandleFunc("POST /api/chirps", cfg.handlerChirps)
 	mux.HandleFunc("GET /api/chirps", cfg.handlerListChirps)
+	mux.HandleFunc("GET /api/chirps/scheduled", cfg.handlerListScheduledChirps)
+	mux.HandleFunc("PUT /api/chirps/{chirpID}/schedule", cfg.handlerEditScheduledChirp)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/schedule", cfg.handlerCancelScheduledChirp)
 	mux.HandleFunc("GET /api/chirps/{chirpID}", cfg.handlerRetrieveChirp)
+	mux.HandleFunc("PUT /api/chirps/{chirpID}", cfg.handlerEditChirp)
+	mux.HandleFunc("PATCH /api/chirps/{chirpID}", cfg.handlerEditChirp)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/history", cfg.handlerChirpHistory)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/thread", cfg.handlerChirpThread)
 	mux.HandleFunc("DELETE /api/chirps/{chirpID}", cfg.handlerDeleteChirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/report", cfg.handlerReportChirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/like", cfg.handlerLikeChirp)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/like", cfg.handlerUnlikeChirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/repost", cfg.handlerRepostChirp)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/repost", cfg.handlerUnrepostChirp)
 	mux.HandleFunc("POST /api/login", cfg.handlerLogin)
+	mux.HandleFunc("POST /api/login/mfa", cfg.handlerLoginMFA)
+	mux.HandleFunc("POST /api/suspension/appeal", cfg.handlerAppealSuspension)
+	mux.HandleFunc("POST /api/2fa/totp/enroll", cfg.handlerEnrollTOTP)
+	mux.HandleFunc("POST /api/2fa/totp/confirm", cfg.handlerConfirmTOTP)
+	mux.HandleFunc("POST /api/2fa/totp/disable", cfg.handlerDisableTOTP)
 	mux.HandleFunc("POST /api/refresh", cfg.handlerRefresh)
 	mux.HandleFunc("POST /api/revoke", cfg.handlerRevoke)
+	mux.HandleFunc("GET /api/sessions", cfg.handlerListSessions)
+	mux.HandleFunc("POST /api/tokens", cfg.handlerCreateToken)
+	mux.HandleFunc("GET /api/tokens", cfg.handlerListTokens)
+	mux.HandleFunc("DELETE /api/tokens/{tokenID}", cfg.handlerRevokeToken)
+	mux.HandleFunc("DELETE /api/sessions/{sessionID}", cfg.handlerRevokeSession)
+	mux.HandleFunc("POST /api/sessions/revoke-all", cfg.handlerRevokeAllSessions)
 	mux.HandleFunc("POST /api/polka/webhooks", cfg.handlerUpgrade)
 
 	err = server.ListenAndServe()
@@ -59,6 +181,45 @@ func main() {
 	}
 }
 
+// appRoot is the directory served under /app/. Everything in it is public.
+const appRoot = "."
+
+func (cfg *apiConfig) appHandler() http.Handler {
+	return cfg.middlewareMetricsInc(http.StripPrefix("/app", http.FileServer(http.Dir(appRoot))))
+}
+
+// privateDir returns the directory named by the env variable, or name under
+// the user's cache directory when it isn't set. It refuses any directory that
+// /app/ would serve, since what's kept there isn't meant to be public.
+func privateDir(env, name string) (string, error) {
+	dir := os.Getenv(env)
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			base = os.TempDir()
+		}
+		dir = filepath.Join(base, "chirpy", name)
+	}
+
+	root, err := filepath.Abs(appRoot)
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return "", err
+	}
+	if rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s %s is served under /app/, so it must be outside %s", env, abs, root)
+	}
+
+	return dir, nil
+}
+
 func (cfg *apiConfig) middlewareMetricsInc(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		cfg.fileserverHits.Add(1)
@@ -68,16 +229,81 @@ func (cfg *apiConfig) middlewareMetricsInc(next http.Handler) http.Handler {
 
 type apiConfig struct {
 	fileserverHits atomic.Int32
+	conn           *sql.DB
 	db             *database.Queries
 	platform       string
-	secret         string
 	polka_key      string
+	moderator      *moderation.Pipeline
+	wordList       *moderation.WordList
+	baseWords      []string
+	media          storage.Store
+	exports        storage.Store
+	keyring        *auth.Keyring
+	keySealer      *auth.KeySealer
+	keyReloadMu    sync.Mutex
+	lastKeyReload  time.Time
+	mailer         mailer.Mailer
+	appURL         string
 }
 
 type Chirp struct {
-	ID        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Body      string    `json:"body"`
-	UserID    uuid.UUID `json:"user_id"`
+	ID         uuid.UUID  `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	Body       string     `json:"body"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Edited     bool       `json:"edited"`
+	EditedAt   *time.Time `json:"edited_at,omitempty"`
+	InReplyTo  *uuid.UUID `json:"in_reply_to"`
+	ReplyCount int32      `json:"reply_count"`
+	Deleted    bool       `json:"deleted"`
+	Hidden     bool       `json:"hidden,omitempty"`
+
+	LikeCount      int32      `json:"like_count"`
+	RepostCount    int32      `json:"repost_count"`
+	ViewerLiked    bool       `json:"viewer_liked"`
+	ViewerReposted bool       `json:"viewer_reposted"`
+	RepostedBy     *uuid.UUID `json:"reposted_by,omitempty"`
+	RepostedAt     *time.Time `json:"reposted_at,omitempty"`
+
+	Draft     bool       `json:"draft,omitempty"`
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+
+	Author   *Author       `json:"author,omitempty"`
+	Entities []ChirpEntity `json:"entities"`
+	Media    []ChirpMedia  `json:"media"`
+}
+
+func databaseChirpToChirp(chirp database.Chirp) Chirp {
+	converted := Chirp{
+		ID:         chirp.ID,
+		CreatedAt:  chirp.CreatedAt,
+		UpdatedAt:  chirp.UpdatedAt,
+		Body:       chirp.Body,
+		UserID:     chirp.UserID,
+		Edited:     chirp.EditedAt.Valid,
+		ReplyCount: chirp.ReplyCount,
+		Deleted:    chirp.DeletedAt.Valid,
+		Hidden:     chirp.HiddenAt.Valid,
+
+		LikeCount:   chirp.LikeCount,
+		RepostCount: chirp.RepostCount,
+
+		Entities: chirpEntities(chirp.Body),
+		Media:    []ChirpMedia{},
+	}
+	if chirp.EditedAt.Valid {
+		converted.EditedAt = &chirp.EditedAt.Time
+	}
+	if chirp.ParentID.Valid {
+		converted.InReplyTo = &chirp.ParentID.UUID
+	}
+	if !chirp.PublishedAt.Valid {
+		converted.Draft = !chirp.PublishAt.Valid
+		if chirp.PublishAt.Valid {
+			converted.PublishAt = &chirp.PublishAt.Time
+		}
+	}
+
+	return converted
 }
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+	"github.com/willthefoollearn/chirpy/internal/storage"
+)
+
+// chdir moves into dir for the rest of the test, since /app/ serves the
+// working directory.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chdir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestAppDoesNotServeExports(t *testing.T) {
+	chdir(t, t.TempDir())
+	cache := t.TempDir()
+	t.Setenv("HOME", cache)
+	t.Setenv("XDG_CACHE_HOME", cache)
+	t.Setenv("MEDIA_STORAGE", "")
+	t.Setenv("EXPORT_DIR", "")
+
+	cfg := &apiConfig{}
+	err := cfg.setupExportStorage()
+	if err != nil {
+		t.Fatalf("setupExportStorage: %v", err)
+	}
+	err = cfg.exports.Put(context.Background(), "export.zip", strings.NewReader("PK"), "application/zip")
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	for _, path := range []string{"/app/exports/", "/app/exports/export.zip"} {
+		rec := httptest.NewRecorder()
+		cfg.appHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want %d", path, rec.Code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestPrivateDirRefusesServedDirectory(t *testing.T) {
+	chdir(t, t.TempDir())
+
+	for _, dir := range []string{".", "exports", "./data/exports"} {
+		t.Setenv("EXPORT_DIR", dir)
+		_, err := privateDir("EXPORT_DIR", "exports")
+		if err == nil {
+			t.Errorf("privateDir(%q) succeeded, want an error", dir)
+		}
+	}
+
+	t.Setenv("EXPORT_DIR", "../exports")
+	_, err := privateDir("EXPORT_DIR", "exports")
+	if err != nil {
+		t.Errorf("privateDir(%q): %v", "../exports", err)
+	}
+}
+
+// testConfig connects to the database named by TEST_DB_URL, which must
+// already be migrated. Tests that need one are skipped without it.
+func testConfig(t *testing.T) *apiConfig {
+	t.Helper()
+	dbURL := os.Getenv("TEST_DB_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DB_URL isn't set")
+	}
+
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return &apiConfig{
+		conn: db,
+		db:   database.New(db),
+	}
+}
+
+func createTestUser(t *testing.T, cfg *apiConfig, password string) database.User {
+	t.Helper()
+	hashedPassword, err := auth.HashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := cfg.db.CreateUser(context.Background(), database.CreateUserParams{
+		Email:          uuid.NewString() + "@example.com",
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cfg.db.DeleteUser(context.Background(), user.ID) })
+
+	return user
+}
+
+func loginFailures(t *testing.T, cfg *apiConfig, key string) int {
+	t.Helper()
+	var failures int
+	err := cfg.conn.QueryRow("SELECT failures FROM login_throttles WHERE key = $1", key).Scan(&failures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return failures
+}
+
+func TestAppealDoesNotResetMFAAttempts(t *testing.T) {
+	cfg := testConfig(t)
+	ctx := context.Background()
+	const password = "correct horse battery staple"
+
+	for _, suspended := range []bool{false, true} {
+		user := createTestUser(t, cfg, password)
+		if suspended {
+			_, err := cfg.db.CreateSuspension(ctx, database.CreateSuspensionParams{
+				UserID: user.ID,
+				Reason: "testing",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Each address gets its own key, so runs don't share a count.
+		remoteAddr := uuid.NewString()
+		newRequest := func(body string) *http.Request {
+			req := httptest.NewRequest(http.MethodPost, "/api/suspension/appeal", strings.NewReader(body))
+			req.RemoteAddr = remoteAddr
+			return req
+		}
+
+		// Wrong two-factor codes are counted the way handlerLoginMFA does.
+		throttle := newLoginThrottle(newRequest(""), user.Email)
+		t.Cleanup(func() {
+			cfg.db.ClearLoginFailures(ctx, throttle.account)
+			cfg.db.ClearLoginFailures(ctx, throttle.ip)
+		})
+		for range 3 {
+			_, err := throttle.begin(ctx, cfg.conn, cfg.db)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		rec := httptest.NewRecorder()
+		cfg.handlerAppealSuspension(rec, newRequest(fmt.Sprintf(
+			`{"email": %q, "password": %q, "appeal": "It wasn't me"}`, user.Email, password,
+		)))
+
+		want := http.StatusNotFound
+		if suspended {
+			want = http.StatusOK
+		}
+		if rec.Code != want {
+			t.Errorf("suspended = %v: appeal = %d, want %d", suspended, rec.Code, want)
+		}
+		if failures := loginFailures(t, cfg, throttle.account); failures != 4 {
+			t.Errorf("suspended = %v: account failures after appeal = %d, want 4", suspended, failures)
+		}
+		// A correct appeal takes its own attempt back off the address; one
+		// from an account that isn't suspended doesn't.
+		wantAddress := 4
+		if suspended {
+			wantAddress = 3
+		}
+		if failures := loginFailures(t, cfg, throttle.ip); failures != wantAddress {
+			t.Errorf("suspended = %v: address failures after appeal = %d, want %d", suspended, failures, wantAddress)
+		}
+	}
+}
+
+func TestUnknownSigningKeyReloadsAreLimited(t *testing.T) {
+	known, _ := auth.GenerateKey()
+	unknown, _ := auth.GenerateKey()
+
+	cfg := &apiConfig{
+		keyring: auth.NewKeyring("chirpy", "chirpy-api"),
+	}
+	cfg.keyring.SetKeys([]auth.Key{known})
+
+	forger := auth.NewKeyring("chirpy", "chirpy-api")
+	forger.SetKeys([]auth.Key{unknown})
+	token, err := forger.MakeJWT(uuid.New(), uuid.New(), 0, auth.AllScopes, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// With a reload just done, another one mustn't reach the database, which
+	// this config doesn't have.
+	cfg.lastKeyReload = time.Now()
+	for range 3 {
+		_, err = cfg.authenticate(context.Background(), token)
+		if !errors.Is(err, auth.ErrUnknownKey) {
+			t.Errorf("authenticate error = %v, want %v", err, auth.ErrUnknownKey)
+		}
+	}
+}
+
+func TestDeletedMediaIsNotServed(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Put(context.Background(), "photo.png", strings.NewReader("not really a png"), "image/png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := middlewareMediaCache(http.StripPrefix("/media", mediaServer{store: store}))
+	get := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/media/photo.png", nil)
+		req.Header.Set("If-None-Match", `"photo.png"`)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get()
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("GET before delete = %d, want %d", rec.Code, http.StatusNotModified)
+	}
+	if cacheControl := rec.Header().Get("Cache-Control"); strings.Contains(cacheControl, "immutable") {
+		t.Errorf("Cache-Control = %s, but media can be deleted", cacheControl)
+	}
+
+	err = store.Delete(context.Background(), "photo.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := get(); rec.Code != http.StatusNotFound {
+		t.Errorf("GET after delete = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestPasswordResetRequestsAreThrottled(t *testing.T) {
+	cfg := testConfig(t)
+	ctx := context.Background()
+
+	// Nobody has this address, so no mail is sent, but requests still count.
+	email := uuid.NewString() + "@example.com"
+	remoteAddr := uuid.NewString()
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/password-reset/request", strings.NewReader(fmt.Sprintf(`{"email": %q}`, email)))
+		req.RemoteAddr = remoteAddr
+		return req
+	}
+	throttle := newPasswordResetThrottle(newRequest(), email)
+	t.Cleanup(func() {
+		cfg.db.ClearLoginFailures(ctx, throttle.account)
+		cfg.db.ClearLoginFailures(ctx, throttle.ip)
+	})
+
+	for i := range resetAccountBackoff.Free + 2 {
+		rec := httptest.NewRecorder()
+		cfg.handlerRequestPasswordReset(rec, newRequest())
+
+		want := http.StatusAccepted
+		if i > resetAccountBackoff.Free {
+			want = http.StatusTooManyRequests
+		}
+		if rec.Code != want {
+			t.Errorf("request %d = %d, want %d", i+1, rec.Code, want)
+		}
+	}
+
+	// Reset requests mustn't lock the address out of logging in.
+	login := newLoginThrottle(newRequest(), email)
+	t.Cleanup(func() {
+		cfg.db.ClearLoginFailures(ctx, login.account)
+		cfg.db.ClearLoginFailures(ctx, login.ip)
+	})
+	lockedUntil, err := login.begin(ctx, cfg.conn, cfg.db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lockedUntil.IsZero() {
+		t.Errorf("login locked until %v after reset requests", lockedUntil)
+	}
+}
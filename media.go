@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/database"
+	"github.com/willthefoollearn/chirpy/internal/storage"
+)
+
+const (
+	maxUploadBytes   = 5 << 20
+	maxMediaPerChirp = 4
+)
+
+type ChirpMedia struct {
+	ID          uuid.UUID `json:"id"`
+	URL         string    `json:"url"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	Width       int32     `json:"width"`
+	Height      int32     `json:"height"`
+}
+
+func databaseMediaToMedia(file database.MediaFile) ChirpMedia {
+	return ChirpMedia{
+		ID:          file.ID,
+		URL:         mediaURL(file.StorageKey),
+		ContentType: file.ContentType,
+		SizeBytes:   file.SizeBytes,
+		Width:       file.Width,
+		Height:      file.Height,
+	}
+}
+
+func mediaURL(storageKey string) string {
+	return "/media/" + storageKey
+}
+
+// setupMediaStorage picks where uploads are kept. MEDIA_STORAGE=s3 sends them
+// to the S3-compatible service described by S3_ENDPOINT, S3_BUCKET, S3_REGION,
+// S3_ACCESS_KEY and S3_SECRET_KEY; anything else keeps them on disk under
+// MEDIA_DIR, which defaults to the user's cache directory. It must not be one
+// /app/ serves, or uploads could be fetched around mediaServer.
+func (cfg *apiConfig) setupMediaStorage() error {
+	if os.Getenv("MEDIA_STORAGE") == "s3" {
+		region := os.Getenv("S3_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+
+		store, err := storage.NewS3Store(
+			os.Getenv("S3_ENDPOINT"),
+			os.Getenv("S3_BUCKET"),
+			region,
+			os.Getenv("S3_ACCESS_KEY"),
+			os.Getenv("S3_SECRET_KEY"),
+		)
+		if err != nil {
+			return err
+		}
+		cfg.media = store
+		return nil
+	}
+
+	dir, err := privateDir("MEDIA_DIR", "media")
+	if err != nil {
+		return err
+	}
+
+	store, err := storage.NewLocalStore(dir)
+	if err != nil {
+		return err
+	}
+	cfg.media = store
+	return nil
+}
+
+// attachChirpMedia links uploads to a new chirp in the order they were given.
+// Every upload has to belong to the author, and none may be listed twice. The
+// status code says how to report a failure.
+func attachChirpMedia(ctx context.Context, db *database.Queries, user, chirpID uuid.UUID, mediaIDs []uuid.UUID) ([]ChirpMedia, int, error) {
+	attached := []ChirpMedia{}
+	if len(mediaIDs) == 0 {
+		return attached, http.StatusOK, nil
+	}
+
+	files, err := db.GetUserMediaFiles(ctx, database.GetUserMediaFilesParams{
+		UserID:   user,
+		MediaIds: mediaIDs,
+	})
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	owned := make(map[uuid.UUID]database.MediaFile, len(files))
+	for _, file := range files {
+		owned[file.ID] = file
+	}
+
+	seen := make(map[uuid.UUID]bool, len(mediaIDs))
+	for i, id := range mediaIDs {
+		file, ok := owned[id]
+		if !ok {
+			return nil, http.StatusBadRequest, fmt.Errorf("media %s not found", id)
+		}
+		if seen[id] {
+			return nil, http.StatusBadRequest, fmt.Errorf("media %s attached twice", id)
+		}
+		seen[id] = true
+
+		err = db.AttachChirpMedia(ctx, database.AttachChirpMediaParams{
+			ChirpID:  chirpID,
+			MediaID:  id,
+			Position: int32(i),
+		})
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		attached = append(attached, databaseMediaToMedia(file))
+	}
+
+	return attached, http.StatusOK, nil
+}
+
+// setChirpMedia loads the attachments for each chirp in display order.
+func (cfg *apiConfig) setChirpMedia(ctx context.Context, chirps []*Chirp) error {
+	if len(chirps) == 0 {
+		return nil
+	}
+
+	chirpIDs := make([]uuid.UUID, 0, len(chirps))
+	for _, chirp := range chirps {
+		chirpIDs = append(chirpIDs, chirp.ID)
+	}
+
+	rows, err := cfg.db.GetChirpMedia(ctx, chirpIDs)
+	if err != nil {
+		return err
+	}
+
+	attached := make(map[uuid.UUID][]ChirpMedia, len(rows))
+	for _, row := range rows {
+		attached[row.ChirpID] = append(attached[row.ChirpID], databaseMediaToMedia(row.MediaFile))
+	}
+
+	for _, chirp := range chirps {
+		if files, ok := attached[chirp.ID]; ok {
+			chirp.Media = files
+		}
+	}
+
+	return nil
+}
+
+// detachChirpMedia takes the attachments off a chirp that's being deleted and
+// drops the uploads nothing else uses, returning their storage keys so the
+// objects can be removed once the transaction commits.
+func detachChirpMedia(ctx context.Context, db *database.Queries, chirpID uuid.UUID) ([]string, error) {
+	mediaIDs, err := db.DeleteChirpMedia(ctx, chirpID)
+	if err != nil || len(mediaIDs) == 0 {
+		return nil, err
+	}
+
+	return db.DeleteUnusedMediaFiles(ctx, mediaIDs)
+}
+
+// deleteStoredMedia removes stored objects whose media_files rows are already
+// gone. A failure only leaves an object nothing links to, so it's logged.
+func (cfg *apiConfig) deleteStoredMedia(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		err := cfg.media.Delete(ctx, key)
+		if err != nil {
+			log.Printf("Unable to delete media %s: %v", key, err)
+		}
+	}
+}
+
+// mediaServer streams stored uploads back out. Keys are random and an upload
+// never changes once stored, so the key itself is a strong ETag. Uploads are
+// deleted along with their chirps, though, so caches have to check back.
+type mediaServer struct {
+	store storage.Store
+}
+
+func (m mediaServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	key := strings.TrimPrefix(req.URL.Path, "/")
+	body, object, err := m.store.Get(req.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to retrieve media", err)
+		return
+	}
+	defer body.Close()
+
+	// Only an upload that still exists is not modified.
+	etag := fmt.Sprintf("%q", key)
+	if req.Header.Get("If-None-Match") == etag {
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", object.ContentType)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if object.Size > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(object.Size, 10))
+	}
+	w.WriteHeader(http.StatusOK)
+
+	if req.Method == http.MethodGet {
+		io.Copy(w, body)
+	}
+}
+
+func middlewareMediaCache(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		next.ServeHTTP(w, req)
+	})
+}
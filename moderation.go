@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/willthefoollearn/chirpy/internal/moderation"
+)
+
+const maxChirpLength = 140
+
+// setupModeration builds the filter pipeline every chirp body goes through.
+// The word list comes from the moderation_words table, plus an optional file
+// named by MODERATION_WORDS_FILE. MODERATION_PATTERNS_FILE adds regular
+// expressions that flag chirps for review, and MODERATION_BLOCKED_DOMAINS is a
+// comma-separated list of domains chirps may not link to.
+func (cfg *apiConfig) setupModeration() error {
+	if path := os.Getenv("MODERATION_WORDS_FILE"); path != "" {
+		words, err := moderation.LoadWordsFile(path)
+		if err != nil {
+			return err
+		}
+		cfg.baseWords = words
+	}
+	cfg.wordList = moderation.NewWordList(moderation.Mask, cfg.baseWords)
+
+	filters := []moderation.Filter{
+		moderation.LengthFilter{Max: maxChirpLength},
+		cfg.wordList,
+	}
+
+	if path := os.Getenv("MODERATION_PATTERNS_FILE"); path != "" {
+		patterns, err := moderation.LoadPatternsFile(path)
+		if err != nil {
+			return err
+		}
+		filters = append(filters, moderation.RegexFilter{
+			Action:   moderation.Flag,
+			Patterns: patterns,
+		})
+	}
+
+	if domains := os.Getenv("MODERATION_BLOCKED_DOMAINS"); domains != "" {
+		filters = append(filters, moderation.LinkBlocklist{
+			Action:  moderation.Reject,
+			Domains: strings.Split(domains, ","),
+		})
+	}
+
+	filters = append(filters, moderation.SpamHeuristic{
+		Action:         moderation.Flag,
+		MaxLinks:       3,
+		MaxTags:        10,
+		MaxRepeatedRun: 12,
+	})
+
+	cfg.moderator = moderation.NewPipeline(filters...)
+
+	return nil
+}
+
+// reloadModerationWords swaps the live word list for the current contents of
+// the moderation_words table.
+func (cfg *apiConfig) reloadModerationWords(ctx context.Context) error {
+	words, err := cfg.db.ListModerationWords(ctx)
+	if err != nil {
+		return err
+	}
+
+	cfg.wordList.SetWords(append(words, cfg.baseWords...))
+	return nil
+}
+
+// watchModerationWords keeps the word list in step with changes made through
+// other server instances.
+func (cfg *apiConfig) watchModerationWords(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		err := cfg.reloadModerationWords(context.Background())
+		if err != nil {
+			log.Printf("Unable to reload moderation words: %v", err)
+		}
+	}
+}
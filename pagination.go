@@ -0,0 +1,70 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/pagination"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+type pageParams struct {
+	Limit          int32
+	AfterCreatedAt sql.NullTime
+	AfterID        uuid.NullUUID
+	AfterRank      sql.NullFloat64
+}
+
+// parsePageParams reads the limit and cursor query parameters shared by every
+// paginated list endpoint.
+func parsePageParams(query url.Values) (pageParams, error) {
+	page := pageParams{
+		Limit: defaultPageLimit,
+	}
+
+	if rawLimit := query.Get("limit"); rawLimit != "" {
+		limit, err := strconv.Atoi(rawLimit)
+		if err != nil || limit < 1 {
+			return pageParams{}, errors.New("limit must be a positive integer")
+		}
+		if limit > maxPageLimit {
+			limit = maxPageLimit
+		}
+		page.Limit = int32(limit)
+	}
+
+	if rawCursor := query.Get("cursor"); rawCursor != "" {
+		cursor, err := pagination.DecodeCursor(rawCursor)
+		if err != nil {
+			return pageParams{}, err
+		}
+		page.AfterCreatedAt = sql.NullTime{Time: cursor.CreatedAt, Valid: true}
+		page.AfterID = uuid.NullUUID{UUID: cursor.ID, Valid: true}
+		if cursor.Rank != nil {
+			page.AfterRank = sql.NullFloat64{Float64: float64(*cursor.Rank), Valid: true}
+		}
+	}
+
+	return page, nil
+}
+
+// setNextPageLink points the client at the page after the given cursor using
+// the same query parameters as the current request.
+func setNextPageLink(w http.ResponseWriter, req *http.Request, cursor pagination.Cursor) {
+	query := req.URL.Query()
+	query.Set("cursor", cursor.Encode())
+	next := url.URL{
+		Path:     req.URL.Path,
+		RawQuery: query.Encode(),
+	}
+	w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", next.String()))
+}
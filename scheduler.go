@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/database"
+)
+
+const publishBatchSize = 100
+
+// parsePublishTime works out when a chirp should go out. Drafts have no
+// publish time, a time in the future schedules the chirp, and anything else
+// publishes it straight away.
+func parsePublishTime(draft bool, publishAt *time.Time) (sql.NullTime, bool, error) {
+	if draft {
+		if publishAt != nil {
+			return sql.NullTime{}, false, errors.New("a draft can't have a publish time")
+		}
+		return sql.NullTime{}, false, nil
+	}
+
+	if publishAt == nil || !publishAt.After(time.Now()) {
+		return sql.NullTime{}, true, nil
+	}
+
+	return sql.NullTime{Time: publishAt.UTC(), Valid: true}, false, nil
+}
+
+// publishChirp makes an unpublished chirp visible. It takes its publish time
+// as its creation time so it lands at the top of feeds rather than wherever
+// it was first written, and its hashtags and mentions are indexed from then.
+func publishChirp(ctx context.Context, db *database.Queries, chirpID uuid.UUID) (database.Chirp, error) {
+	chirp, err := db.PublishChirp(ctx, chirpID)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+
+	err = saveChirpEntities(ctx, db, chirp)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+
+	return chirp, nil
+}
+
+// publishDueChirps publishes every scheduled chirp whose time has come. Rows
+// are claimed with FOR UPDATE SKIP LOCKED, so several server instances can
+// run the scheduler side by side without publishing a chirp twice, and
+// anything that fell due while no server was running goes out on the next
+// pass. Chirps from suspended users stay scheduled and go out once the
+// suspension ends.
+func (cfg *apiConfig) publishDueChirps(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		published, err := cfg.publishDueBatch(ctx)
+		total += published
+		if err != nil || published < publishBatchSize {
+			return total, err
+		}
+	}
+}
+
+func (cfg *apiConfig) publishDueBatch(ctx context.Context) (int, error) {
+	tx, err := cfg.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	due, err := qtx.ClaimDueChirps(ctx, publishBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, chirp := range due {
+		_, err = publishChirp(ctx, qtx, chirp.ID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return len(due), nil
+}
+
+// runScheduler publishes due chirps on startup and then every interval.
+func (cfg *apiConfig) runScheduler(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		published, err := cfg.publishDueChirps(context.Background())
+		if err != nil {
+			log.Printf("Unable to publish scheduled chirps: %v", err)
+		}
+		if published > 0 {
+			log.Printf("Published %d scheduled chirps", published)
+		}
+
+		<-ticker.C
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/willthefoollearn/chirpy/internal/database"
+)
+
+func TestSuspendedUsersScheduledChirpsWait(t *testing.T) {
+	cfg := testConfig(t)
+	ctx := context.Background()
+
+	user := createTestUser(t, cfg, "correct horse battery staple")
+	scheduled, err := cfg.db.CreateUnpublishedChirp(ctx, database.CreateUnpublishedChirpParams{
+		Body:      "Posted from the future",
+		UserID:    user.ID,
+		PublishAt: sql.NullTime{Time: time.Now().UTC().Add(-time.Minute), Valid: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cfg.db.CreateSuspension(ctx, database.CreateSuspensionParams{
+		UserID: user.ID,
+		Reason: "testing",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	published := func() bool {
+		t.Helper()
+		_, err := cfg.publishDueChirps(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chirp, err := cfg.db.RetrieveChirp(ctx, scheduled.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return chirp.PublishedAt.Valid
+	}
+
+	if published() {
+		t.Errorf("chirp was published while its author is suspended")
+	}
+
+	_, err = cfg.db.LiftSuspension(ctx, database.LiftSuspensionParams{UserID: user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !published() {
+		t.Errorf("chirp wasn't published once the suspension was lifted")
+	}
+}
@@ -0,0 +1,64 @@
+-- name: ScheduleAccountDeletion :one
+UPDATE users
+SET delete_after = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: CancelAccountDeletion :one
+UPDATE users
+SET delete_after = NULL, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: ClaimDueAccountDeletion :one
+SELECT id FROM users
+WHERE delete_after <= NOW()
+ORDER BY delete_after
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: RevokeAllPersonalAccessTokens :exec
+UPDATE personal_access_tokens
+SET revoked_at = NOW()
+WHERE user_id = $1
+AND revoked_at IS NULL;
+
+-- name: ReleaseUserLikes :exec
+WITH deleted AS (
+    DELETE FROM likes
+    WHERE user_id = $1
+    RETURNING chirp_id
+)
+UPDATE chirps
+SET like_count = like_count - 1
+WHERE id IN (SELECT chirp_id FROM deleted);
+
+-- name: ReleaseUserReposts :exec
+WITH deleted AS (
+    DELETE FROM reposts
+    WHERE user_id = $1
+    RETURNING chirp_id
+)
+UPDATE chirps
+SET repost_count = repost_count - 1
+WHERE id IN (SELECT chirp_id FROM deleted);
+
+-- name: ReleaseUserReplies :exec
+UPDATE chirps
+SET reply_count = chirps.reply_count - replies.reply_count
+FROM (
+    SELECT parent_id, COUNT(*)::int AS reply_count FROM chirps
+    WHERE chirps.user_id = sqlc.arg(user_id)
+    AND parent_id IS NOT NULL
+    GROUP BY parent_id
+) AS replies
+WHERE chirps.id = replies.parent_id
+AND chirps.user_id <> sqlc.arg(user_id);
+
+-- name: ListUserStorageKeys :many
+SELECT storage_key FROM media_files
+WHERE user_id = $1;
+
+-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1;
@@ -0,0 +1,18 @@
+-- name: RecordAuditEvent :exec
+INSERT INTO moderation_audit_log (id, created_at, actor_id, action, target_type, target_id, note)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+);
+
+-- name: ListAuditEvents :many
+SELECT * FROM moderation_audit_log
+WHERE (sqlc.narg(target_id)::text IS NULL OR target_id = sqlc.narg(target_id))
+AND (sqlc.narg(after_created_at)::timestamp IS NULL OR (created_at, id) < (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(row_limit);
@@ -0,0 +1,88 @@
+-- name: CreateDataExport :one
+INSERT INTO data_exports (id, user_id, status, created_at)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    'pending',
+    NOW()
+)
+RETURNING *;
+
+-- name: GetLatestDataExport :one
+SELECT * FROM data_exports
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: ClaimDataExport :one
+UPDATE data_exports
+SET status = 'running', started_at = NOW()
+WHERE id = (
+    SELECT id FROM data_exports
+    WHERE status = 'pending'
+    OR (status = 'running' AND started_at < NOW() - INTERVAL '1 hour')
+    ORDER BY created_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteDataExport :exec
+UPDATE data_exports
+SET status = 'ready', completed_at = NOW(), storage_key = $2, size_bytes = $3, expires_at = $4
+WHERE id = $1;
+
+-- name: FailDataExport :exec
+UPDATE data_exports
+SET status = 'failed', completed_at = NOW(), error = $2
+WHERE id = $1;
+
+-- name: DeleteExpiredDataExports :many
+DELETE FROM data_exports
+WHERE expires_at < NOW()
+RETURNING storage_key;
+
+-- name: ListUserExportKeys :many
+SELECT storage_key FROM data_exports
+WHERE user_id = $1
+AND storage_key IS NOT NULL;
+
+-- name: GetExportSize :one
+SELECT
+    (SELECT COUNT(*) FROM chirps WHERE chirps.user_id = $1)::int AS chirp_count,
+    (SELECT COALESCE(SUM(size_bytes), 0) FROM media_files WHERE media_files.user_id = $1)::bigint AS media_bytes;
+
+-- name: ExportUserChirps :many
+SELECT * FROM chirps
+WHERE user_id = $1
+ORDER BY created_at, id;
+
+-- name: ExportUserChirpRevisions :many
+SELECT chirp_revisions.* FROM chirp_revisions
+JOIN chirps ON chirps.id = chirp_revisions.chirp_id
+WHERE chirps.user_id = $1
+ORDER BY chirp_revisions.created_at, chirp_revisions.id;
+
+-- name: ExportUserMediaFiles :many
+SELECT * FROM media_files
+WHERE user_id = $1
+ORDER BY created_at, id;
+
+-- name: ExportUserSessions :many
+SELECT family_id, created_at, last_used_at, expires_at, revoked_at, user_agent, ip_address FROM refresh_tokens
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: ExportSubscriptionEvents :many
+SELECT * FROM subscription_events
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: RecordSubscriptionEvent :exec
+INSERT INTO subscription_events (id, user_id, event, created_at)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    NOW()
+);
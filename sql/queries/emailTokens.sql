@@ -0,0 +1,41 @@
+-- name: CreateEmailToken :one
+INSERT INTO email_tokens (token_hash, user_id, purpose, email, created_at, expires_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    NOW(),
+    $5
+)
+RETURNING *;
+
+-- name: ExpireEmailTokens :exec
+UPDATE email_tokens
+SET used_at = NOW()
+WHERE user_id = $1
+AND purpose = $2
+AND used_at IS NULL;
+
+-- name: LockEmailToken :one
+SELECT * FROM email_tokens
+WHERE token_hash = $1
+AND purpose = $2
+FOR UPDATE;
+
+-- name: UseEmailToken :exec
+UPDATE email_tokens
+SET used_at = NOW()
+WHERE token_hash = $1;
+
+-- name: UpdatePassword :one
+UPDATE users
+SET hashed_password = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: VerifyEmail :one
+UPDATE users
+SET email = $2, email_verified_at = NOW(), updated_at = NOW()
+WHERE id = $1
+RETURNING *;
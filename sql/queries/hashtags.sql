@@ -0,0 +1,20 @@
+-- name: AddChirpHashtags :exec
+INSERT INTO chirp_hashtags (chirp_id, tag, created_at)
+SELECT @chirp_id::uuid, unnest(@tags::text[]), @created_at::timestamp
+ON CONFLICT (chirp_id, tag) DO NOTHING;
+
+-- name: DeleteChirpHashtags :exec
+DELETE FROM chirp_hashtags
+WHERE chirp_id = $1;
+
+-- name: ListHashtagChirps :many
+SELECT sqlc.embed(chirps), chirp_hashtags.created_at AS tagged_at FROM chirp_hashtags
+JOIN chirps ON chirps.id = chirp_hashtags.chirp_id
+WHERE chirp_hashtags.tag = sqlc.arg(tag)
+AND chirps.deleted_at IS NULL
+AND chirps.published_at IS NOT NULL
+AND NOT hidden_from_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id)
+AND NOT removed_for_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id, chirps.hidden_at)
+AND (sqlc.narg(after_created_at)::timestamp IS NULL OR (chirp_hashtags.created_at, chirp_hashtags.chirp_id) < (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY chirp_hashtags.created_at DESC, chirp_hashtags.chirp_id DESC
+LIMIT sqlc.arg(row_limit);
@@ -0,0 +1,35 @@
+-- name: ListAuthorChirpsAsc :many
+SELECT sqlc.embed(chirps), activity.reposted_by, activity.activity_at FROM (
+    SELECT id AS chirp_id, NULL::uuid AS reposted_by, created_at AS activity_at FROM chirps
+    WHERE chirps.user_id = sqlc.arg(author_id)
+    UNION ALL
+    SELECT chirp_id, user_id, created_at FROM reposts
+    WHERE reposts.user_id = sqlc.arg(author_id)
+) AS activity
+JOIN chirps ON chirps.id = activity.chirp_id
+WHERE chirps.deleted_at IS NULL
+AND chirps.published_at IS NOT NULL
+AND NOT hidden_from_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id)
+AND NOT removed_for_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id, chirps.hidden_at)
+AND NOT hidden_from_viewer(sqlc.narg(viewer_id)::uuid, sqlc.arg(author_id))
+AND (sqlc.narg(after_created_at)::timestamp IS NULL OR (activity.activity_at, chirps.id) > (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY activity.activity_at ASC, chirps.id ASC
+LIMIT sqlc.arg(row_limit);
+
+-- name: ListAuthorChirpsDesc :many
+SELECT sqlc.embed(chirps), activity.reposted_by, activity.activity_at FROM (
+    SELECT id AS chirp_id, NULL::uuid AS reposted_by, created_at AS activity_at FROM chirps
+    WHERE chirps.user_id = sqlc.arg(author_id)
+    UNION ALL
+    SELECT chirp_id, user_id, created_at FROM reposts
+    WHERE reposts.user_id = sqlc.arg(author_id)
+) AS activity
+JOIN chirps ON chirps.id = activity.chirp_id
+WHERE chirps.deleted_at IS NULL
+AND chirps.published_at IS NOT NULL
+AND NOT hidden_from_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id)
+AND NOT removed_for_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id, chirps.hidden_at)
+AND NOT hidden_from_viewer(sqlc.narg(viewer_id)::uuid, sqlc.arg(author_id))
+AND (sqlc.narg(after_created_at)::timestamp IS NULL OR (activity.activity_at, chirps.id) < (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY activity.activity_at DESC, chirps.id DESC
+LIMIT sqlc.arg(row_limit);
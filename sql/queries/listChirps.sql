@@ -0,0 +1,19 @@
+-- name: ListChirpsAsc :many
+SELECT * FROM chirps
+WHERE deleted_at IS NULL
+AND published_at IS NOT NULL
+AND NOT hidden_from_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id)
+AND NOT removed_for_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id, chirps.hidden_at)
+AND (sqlc.narg(after_created_at)::timestamp IS NULL OR (created_at, id) > (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(row_limit);
+
+-- name: ListChirpsDesc :many
+SELECT * FROM chirps
+WHERE deleted_at IS NULL
+AND published_at IS NOT NULL
+AND NOT hidden_from_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id)
+AND NOT removed_for_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id, chirps.hidden_at)
+AND (sqlc.narg(after_created_at)::timestamp IS NULL OR (created_at, id) < (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(row_limit);
@@ -0,0 +1,33 @@
+-- name: RecordLoginFailure :one
+INSERT INTO login_throttles (key, failures, last_failure_at)
+VALUES (
+    $1,
+    1,
+    NOW()
+)
+ON CONFLICT (key) DO UPDATE
+SET failures = CASE
+        WHEN login_throttles.last_failure_at < NOW() - INTERVAL '1 day' THEN 1
+        ELSE login_throttles.failures + 1
+    END,
+    last_failure_at = NOW()
+RETURNING failures, locked_until;
+
+-- name: LockLogin :exec
+UPDATE login_throttles
+SET locked_until = GREATEST(locked_until, @locked_until::timestamp)
+WHERE key = @key;
+
+-- name: ForgiveLoginFailure :exec
+UPDATE login_throttles
+SET failures = GREATEST(failures - 1, 0)
+WHERE key = $1;
+
+-- name: ClearLoginFailures :exec
+DELETE FROM login_throttles
+WHERE key = $1;
+
+-- name: DeleteStaleLoginThrottles :exec
+DELETE FROM login_throttles
+WHERE last_failure_at < NOW() - INTERVAL '1 day'
+AND (locked_until IS NULL OR locked_until < NOW());
@@ -1,10 +1,15 @@
 -- name: MakeRefreshToken :one
-INSERT INTO refresh_tokens (token, created_at, updated_at, user_id, expires_at)
+INSERT INTO refresh_tokens (token_hash, created_at, updated_at, user_id, expires_at, family_id, parent_token_hash, user_agent, ip_address, last_used_at)
 VALUES (
     $1,
     NOW(),
     NOW(),
     $2,
-    NOW() + INTERVAL '60 day'
+    NOW() + INTERVAL '60 day',
+    $3,
+    $4,
+    $5,
+    $6,
+    NOW()
 )
-RETURNING *;
\ No newline at end of file
+RETURNING *;
@@ -0,0 +1,40 @@
+-- name: CreateMediaFile :one
+INSERT INTO media_files (id, created_at, user_id, storage_key, content_type, size_bytes, width, height)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+RETURNING *;
+
+-- name: GetUserMediaFiles :many
+SELECT * FROM media_files
+WHERE user_id = @user_id
+AND id = ANY(@media_ids::uuid[]);
+
+-- name: AttachChirpMedia :exec
+INSERT INTO chirp_media (chirp_id, media_id, position)
+VALUES ($1, $2, $3);
+
+-- name: GetChirpMedia :many
+SELECT chirp_media.chirp_id, sqlc.embed(media_files) FROM chirp_media
+JOIN media_files ON media_files.id = chirp_media.media_id
+WHERE chirp_media.chirp_id = ANY(@chirp_ids::uuid[])
+ORDER BY chirp_media.chirp_id, chirp_media.position;
+
+-- name: DeleteChirpMedia :many
+DELETE FROM chirp_media
+WHERE chirp_id = $1
+RETURNING media_id;
+
+-- name: DeleteUnusedMediaFiles :many
+DELETE FROM media_files
+WHERE id = ANY(@media_ids::uuid[])
+AND NOT EXISTS (SELECT 1 FROM chirp_media WHERE chirp_media.media_id = media_files.id)
+AND NOT EXISTS (SELECT 1 FROM users WHERE users.avatar_media_id = media_files.id)
+RETURNING storage_key;
@@ -0,0 +1,43 @@
+-- name: AddChirpMentions :exec
+INSERT INTO chirp_mentions (chirp_id, handle, user_id, created_at)
+SELECT @chirp_id::uuid, handles.handle, users.id, @created_at::timestamp
+FROM unnest(@handles::text[]) AS handles(handle)
+LEFT JOIN users ON LOWER(users.handle) = handles.handle
+AND NOT EXISTS (
+    SELECT 1 FROM user_relations
+    JOIN chirps ON chirps.id = @chirp_id::uuid
+    WHERE user_relations.user_id = users.id
+    AND user_relations.target_id = chirps.user_id
+    AND user_relations.kind = 'block'
+)
+ON CONFLICT (chirp_id, handle) DO NOTHING;
+
+-- name: DeleteChirpMentions :exec
+DELETE FROM chirp_mentions
+WHERE chirp_id = $1;
+
+-- name: ListUserMentions :many
+SELECT sqlc.embed(chirps), chirp_mentions.created_at AS mentioned_at FROM chirp_mentions
+JOIN chirps ON chirps.id = chirp_mentions.chirp_id
+WHERE chirp_mentions.user_id = sqlc.arg(user_id)::uuid
+AND chirps.deleted_at IS NULL
+AND chirps.published_at IS NOT NULL
+AND NOT hidden_from_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id)
+AND NOT removed_for_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id, chirps.hidden_at)
+AND (sqlc.narg(after_created_at)::timestamp IS NULL OR (chirp_mentions.created_at, chirp_mentions.chirp_id) < (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY chirp_mentions.created_at DESC, chirp_mentions.chirp_id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: ResolveChirpMentions :exec
+UPDATE chirp_mentions
+SET user_id = @user_id
+FROM chirps
+WHERE chirps.id = chirp_mentions.chirp_id
+AND chirp_mentions.handle = LOWER(@handle)
+AND chirp_mentions.user_id IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM user_relations
+    WHERE user_relations.user_id = @user_id
+    AND user_relations.target_id = chirps.user_id
+    AND user_relations.kind = 'block'
+);
@@ -0,0 +1,19 @@
+-- name: CreateModerationFlag :exec
+INSERT INTO moderation_flags (id, created_at, chirp_id, reason)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2
+);
+
+-- name: ListOpenModerationFlags :many
+SELECT * FROM moderation_flags
+WHERE resolved_at IS NULL
+ORDER BY created_at ASC;
+
+-- name: ResolveModerationFlag :execrows
+UPDATE moderation_flags
+SET resolved_at = NOW()
+WHERE id = $1
+AND resolved_at IS NULL;
@@ -0,0 +1,23 @@
+-- name: GetUserIDByHandle :one
+SELECT id FROM users
+WHERE LOWER(handle) = LOWER($1);
+
+-- name: GetUserProfile :one
+SELECT users.id, users.created_at, users.handle, users.display_name, users.bio, media_files.storage_key AS avatar_key,
+    (SELECT COUNT(*) FROM follows WHERE follows.followee_id = users.id)::int AS follower_count,
+    (SELECT COUNT(*) FROM follows WHERE follows.follower_id = users.id)::int AS following_count
+FROM users
+LEFT JOIN media_files ON media_files.id = users.avatar_media_id
+WHERE users.id = $1;
+
+-- name: GetChirpAuthors :many
+SELECT users.id, users.handle, users.display_name, media_files.storage_key AS avatar_key
+FROM users
+LEFT JOIN media_files ON media_files.id = users.avatar_media_id
+WHERE users.id = ANY(@user_ids::uuid[]);
+
+-- name: UpdateUserProfile :one
+UPDATE users
+SET handle = $2, display_name = $3, bio = $4, avatar_media_id = $5, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,37 @@
+-- name: IncrementReplyCount :execrows
+UPDATE chirps
+SET reply_count = reply_count + 1
+WHERE id = $1
+AND deleted_at IS NULL
+AND published_at IS NOT NULL
+AND hidden_at IS NULL;
+
+-- name: DecrementReplyCount :exec
+UPDATE chirps
+SET reply_count = reply_count - 1
+WHERE id = $1;
+
+-- name: TombstoneChirp :one
+UPDATE chirps
+SET body = '', updated_at = NOW(), deleted_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetChirpThread :many
+WITH RECURSIVE thread(id, depth) AS (
+    SELECT chirps.id, 0 FROM chirps
+    WHERE chirps.id = sqlc.arg(root_id)
+    AND chirps.published_at IS NOT NULL
+    AND NOT hidden_from_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id)
+    AND NOT removed_for_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id, chirps.hidden_at)
+    UNION ALL
+    SELECT chirps.id, thread.depth + 1 FROM chirps
+    JOIN thread ON chirps.parent_id = thread.id
+    WHERE thread.depth < sqlc.arg(max_depth)::int
+    AND NOT hidden_from_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id)
+    AND NOT removed_for_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id, chirps.hidden_at)
+)
+SELECT sqlc.embed(chirps), thread.depth FROM thread
+JOIN chirps ON chirps.id = thread.id
+ORDER BY thread.depth, chirps.created_at, chirps.id
+LIMIT sqlc.arg(row_limit);
@@ -0,0 +1,47 @@
+-- name: CreateReport :one
+INSERT INTO reports (id, created_at, reporter_id, user_id, chirp_id, reason, details)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+RETURNING *;
+
+-- name: ListReports :many
+SELECT * FROM reports
+WHERE (sqlc.arg(resolved)::boolean = (resolved_at IS NOT NULL))
+AND (sqlc.narg(assigned_to)::uuid IS NULL OR assigned_to = sqlc.narg(assigned_to))
+AND (sqlc.narg(after_created_at)::timestamp IS NULL OR (created_at, id) > (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(row_limit);
+
+-- name: AssignReport :one
+UPDATE reports
+SET assigned_to = $2, assigned_at = NOW()
+WHERE id = $1
+AND resolved_at IS NULL
+RETURNING *;
+
+-- name: ResolveReport :one
+UPDATE reports
+SET resolution = $2, resolved_by = $3, resolved_at = NOW()
+WHERE id = $1
+AND resolved_at IS NULL
+RETURNING *;
+
+-- name: HideChirp :one
+UPDATE chirps
+SET hidden_at = COALESCE(hidden_at, NOW())
+WHERE id = $1
+AND deleted_at IS NULL
+RETURNING *;
+
+-- name: UnhideChirp :one
+UPDATE chirps
+SET hidden_at = NULL
+WHERE id = $1
+RETURNING *;
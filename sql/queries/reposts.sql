@@ -0,0 +1,26 @@
+-- name: RepostChirp :exec
+WITH inserted AS (
+    INSERT INTO reposts (user_id, chirp_id, created_at)
+    VALUES ($1, $2, NOW())
+    ON CONFLICT (user_id, chirp_id) DO NOTHING
+    RETURNING chirp_id
+)
+UPDATE chirps
+SET repost_count = repost_count + 1
+WHERE id IN (SELECT chirp_id FROM inserted);
+
+-- name: UnrepostChirp :exec
+WITH deleted AS (
+    DELETE FROM reposts
+    WHERE user_id = $1
+    AND chirp_id = $2
+    RETURNING chirp_id
+)
+UPDATE chirps
+SET repost_count = repost_count - 1
+WHERE id IN (SELECT chirp_id FROM deleted);
+
+-- name: GetViewerReposts :many
+SELECT chirp_id FROM reposts
+WHERE user_id = @user_id
+AND chirp_id = ANY(@chirp_ids::uuid[]);
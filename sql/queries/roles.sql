@@ -0,0 +1,15 @@
+-- name: GetUserRole :one
+SELECT role FROM users
+WHERE id = $1;
+
+-- name: SetUserRole :one
+UPDATE users
+SET role = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetUserRoleByEmail :one
+UPDATE users
+SET role = $2, updated_at = NOW()
+WHERE email = $1
+RETURNING *;
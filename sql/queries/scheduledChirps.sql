@@ -0,0 +1,38 @@
+-- name: ListUnpublishedChirps :many
+SELECT * FROM chirps
+WHERE user_id = $1
+AND published_at IS NULL
+ORDER BY publish_at ASC NULLS LAST, created_at ASC, id ASC;
+
+-- name: UpdateUnpublishedChirp :one
+UPDATE chirps
+SET body = $2, publish_at = $3, updated_at = NOW()
+WHERE id = $1
+AND published_at IS NULL
+RETURNING *;
+
+-- name: DeleteUnpublishedChirp :execrows
+DELETE FROM chirps
+WHERE id = $1
+AND user_id = $2
+AND published_at IS NULL;
+
+-- name: PublishChirp :one
+UPDATE chirps
+SET created_at = NOW(), updated_at = NOW(), publish_at = NULL, published_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: ClaimDueChirps :many
+SELECT * FROM chirps
+WHERE published_at IS NULL
+AND publish_at <= NOW()
+AND NOT EXISTS (
+    SELECT 1 FROM suspensions
+    WHERE suspensions.user_id = chirps.user_id
+    AND suspensions.lifted_at IS NULL
+    AND (suspensions.expires_at IS NULL OR suspensions.expires_at > NOW())
+)
+ORDER BY publish_at ASC
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
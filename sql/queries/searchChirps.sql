@@ -0,0 +1,65 @@
+-- name: SearchChirpsByRelevance :many
+SELECT sqlc.embed(chirps),
+    ts_rank(chirps.search_vector, websearch_to_tsquery('english', sqlc.arg(query))) AS rank,
+    ts_headline(
+        'english',
+        replace(replace(replace(chirps.body, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+        websearch_to_tsquery('english', sqlc.arg(query)),
+        'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'
+    ) AS snippet
+FROM chirps
+WHERE chirps.search_vector @@ websearch_to_tsquery('english', sqlc.arg(query))
+AND chirps.deleted_at IS NULL
+AND chirps.published_at IS NOT NULL
+AND NOT hidden_from_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id)
+AND NOT removed_for_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id, chirps.hidden_at)
+AND (sqlc.narg(author_id)::uuid IS NULL OR chirps.user_id = sqlc.narg(author_id))
+AND (sqlc.narg(since)::timestamp IS NULL OR chirps.created_at >= sqlc.narg(since))
+AND (sqlc.narg(until)::timestamp IS NULL OR chirps.created_at < sqlc.narg(until))
+AND (sqlc.narg(after_created_at)::timestamp IS NULL OR (ts_rank(chirps.search_vector, websearch_to_tsquery('english', sqlc.arg(query))), chirps.created_at, chirps.id) < (sqlc.narg(after_rank)::real, sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY rank DESC, chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: SearchChirpsAsc :many
+SELECT sqlc.embed(chirps),
+    ts_rank(chirps.search_vector, websearch_to_tsquery('english', sqlc.arg(query))) AS rank,
+    ts_headline(
+        'english',
+        replace(replace(replace(chirps.body, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+        websearch_to_tsquery('english', sqlc.arg(query)),
+        'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'
+    ) AS snippet
+FROM chirps
+WHERE chirps.search_vector @@ websearch_to_tsquery('english', sqlc.arg(query))
+AND chirps.deleted_at IS NULL
+AND chirps.published_at IS NOT NULL
+AND NOT hidden_from_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id)
+AND NOT removed_for_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id, chirps.hidden_at)
+AND (sqlc.narg(author_id)::uuid IS NULL OR chirps.user_id = sqlc.narg(author_id))
+AND (sqlc.narg(since)::timestamp IS NULL OR chirps.created_at >= sqlc.narg(since))
+AND (sqlc.narg(until)::timestamp IS NULL OR chirps.created_at < sqlc.narg(until))
+AND (sqlc.narg(after_created_at)::timestamp IS NULL OR (chirps.created_at, chirps.id) > (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY chirps.created_at ASC, chirps.id ASC
+LIMIT sqlc.arg(row_limit);
+
+-- name: SearchChirpsDesc :many
+SELECT sqlc.embed(chirps),
+    ts_rank(chirps.search_vector, websearch_to_tsquery('english', sqlc.arg(query))) AS rank,
+    ts_headline(
+        'english',
+        replace(replace(replace(chirps.body, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+        websearch_to_tsquery('english', sqlc.arg(query)),
+        'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'
+    ) AS snippet
+FROM chirps
+WHERE chirps.search_vector @@ websearch_to_tsquery('english', sqlc.arg(query))
+AND chirps.deleted_at IS NULL
+AND chirps.published_at IS NOT NULL
+AND NOT hidden_from_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id)
+AND NOT removed_for_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id, chirps.hidden_at)
+AND (sqlc.narg(author_id)::uuid IS NULL OR chirps.user_id = sqlc.narg(author_id))
+AND (sqlc.narg(since)::timestamp IS NULL OR chirps.created_at >= sqlc.narg(since))
+AND (sqlc.narg(until)::timestamp IS NULL OR chirps.created_at < sqlc.narg(until))
+AND (sqlc.narg(after_created_at)::timestamp IS NULL OR (chirps.created_at, chirps.id) < (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(row_limit);
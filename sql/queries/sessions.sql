@@ -0,0 +1,46 @@
+-- name: ListUserSessions :many
+SELECT refresh_tokens.family_id, families.started_at, refresh_tokens.last_used_at, refresh_tokens.expires_at, refresh_tokens.user_agent, refresh_tokens.ip_address
+FROM refresh_tokens
+JOIN (
+    SELECT family_id, MIN(created_at)::timestamp AS started_at FROM refresh_tokens
+    WHERE user_id = sqlc.arg(user_id)
+    GROUP BY family_id
+) AS families ON families.family_id = refresh_tokens.family_id
+WHERE refresh_tokens.user_id = sqlc.arg(user_id)
+AND refresh_tokens.revoked_at IS NULL
+AND refresh_tokens.expires_at > NOW()
+ORDER BY refresh_tokens.last_used_at DESC;
+
+-- name: RevokeUserSession :execrows
+UPDATE refresh_tokens
+SET revoked_at = NOW(),
+updated_at = NOW()
+WHERE family_id = $1
+AND user_id = $2
+AND revoked_at IS NULL;
+
+-- name: RevokeAllUserSessions :exec
+UPDATE refresh_tokens
+SET revoked_at = NOW(),
+updated_at = NOW()
+WHERE user_id = $1
+AND revoked_at IS NULL;
+
+-- name: RevokeOtherUserSessions :exec
+UPDATE refresh_tokens
+SET revoked_at = NOW(),
+updated_at = NOW()
+WHERE user_id = $1
+AND family_id <> $2
+AND revoked_at IS NULL;
+
+-- name: IncrementTokenVersion :one
+UPDATE users
+SET token_version = token_version + 1,
+updated_at = NOW()
+WHERE id = $1
+RETURNING token_version;
+
+-- name: GetUserTokenVersion :one
+SELECT token_version FROM users
+WHERE id = $1;
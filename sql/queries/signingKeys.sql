@@ -0,0 +1,18 @@
+-- name: CreateSigningKey :exec
+INSERT INTO signing_keys (id, created_at, sealed_private_key)
+VALUES ($1, $2, $3);
+
+-- name: ListSigningKeys :many
+SELECT * FROM signing_keys
+WHERE retired_at IS NULL
+OR retired_at > NOW() - INTERVAL '1 day'
+ORDER BY created_at DESC;
+
+-- name: RetireSigningKeys :exec
+UPDATE signing_keys
+SET retired_at = NOW()
+WHERE retired_at IS NULL;
+
+-- name: DeleteRetiredSigningKeys :exec
+DELETE FROM signing_keys
+WHERE retired_at <= NOW() - INTERVAL '1 day';
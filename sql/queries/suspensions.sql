@@ -0,0 +1,48 @@
+-- name: CreateSuspension :one
+INSERT INTO suspensions (id, created_at, user_id, issued_by, reason, expires_at, hide_chirps)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+RETURNING *;
+
+-- name: GetActiveSuspension :one
+SELECT * FROM suspensions
+WHERE user_id = $1
+AND lifted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW());
+
+-- name: LiftSuspension :one
+UPDATE suspensions
+SET lifted_at = NOW(), lifted_by = $2
+WHERE user_id = $1
+AND lifted_at IS NULL
+RETURNING *;
+
+-- name: LiftExpiredSuspensions :many
+UPDATE suspensions
+SET lifted_at = expires_at
+WHERE lifted_at IS NULL
+AND expires_at <= NOW()
+RETURNING *;
+
+-- name: AppealSuspension :one
+UPDATE suspensions
+SET appeal = $2, appealed_at = NOW()
+WHERE id = $1
+AND appeal IS NULL
+RETURNING *;
+
+-- name: ListActiveSuspensions :many
+SELECT * FROM suspensions
+WHERE lifted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+AND (NOT sqlc.arg(appealed_only)::boolean OR appealed_at IS NOT NULL)
+AND (sqlc.narg(after_created_at)::timestamp IS NULL OR (created_at, id) > (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(row_limit);
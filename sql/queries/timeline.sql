@@ -0,0 +1,12 @@
+-- name: ListTimeline :many
+SELECT chirps.* FROM chirps
+JOIN follows
+ON chirps.user_id = follows.followee_id
+WHERE follows.follower_id = sqlc.arg(user_id)
+AND chirps.deleted_at IS NULL
+AND chirps.published_at IS NOT NULL
+AND NOT hidden_from_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id)
+AND NOT removed_for_viewer(sqlc.narg(viewer_id)::uuid, chirps.user_id, chirps.hidden_at)
+AND (sqlc.narg(after_created_at)::timestamp IS NULL OR (chirps.created_at, chirps.id) < (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(row_limit);
@@ -0,0 +1,61 @@
+-- name: SetTOTPSecret :execrows
+UPDATE users
+SET totp_secret = $2, updated_at = NOW()
+WHERE id = $1
+AND totp_enabled_at IS NULL;
+
+-- name: EnableTOTP :exec
+UPDATE users
+SET totp_enabled_at = NOW(), totp_last_step = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: DisableTOTP :exec
+UPDATE users
+SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = 0, updated_at = NOW()
+WHERE id = $1;
+
+-- name: UseTOTPStep :execrows
+UPDATE users
+SET totp_last_step = $2
+WHERE id = $1
+AND totp_last_step < $2;
+
+-- name: AddRecoveryCodes :exec
+INSERT INTO recovery_codes (code_hash, user_id, created_at)
+SELECT unnest(@code_hashes::text[]), @user_id::uuid, NOW();
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes
+WHERE user_id = $1;
+
+-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes
+SET used_at = NOW()
+WHERE user_id = $1
+AND code_hash = $2
+AND used_at IS NULL;
+
+-- name: CreateMFAChallenge :one
+INSERT INTO mfa_challenges (token_hash, user_id, created_at, expires_at)
+VALUES (
+    $1,
+    $2,
+    NOW(),
+    NOW() + INTERVAL '5 minutes'
+)
+RETURNING *;
+
+-- name: LockMFAChallenge :one
+SELECT * FROM mfa_challenges
+WHERE token_hash = $1
+FOR UPDATE;
+
+-- name: RecordMFAAttempt :exec
+UPDATE mfa_challenges
+SET attempts = attempts + 1
+WHERE token_hash = $1;
+
+-- name: UseMFAChallenge :exec
+UPDATE mfa_challenges
+SET used_at = NOW()
+WHERE token_hash = $1;
@@ -0,0 +1,42 @@
+-- name: AddUserRelation :exec
+INSERT INTO user_relations (user_id, target_id, kind, created_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    NOW()
+)
+ON CONFLICT (user_id, kind, target_id) DO NOTHING;
+
+-- name: RemoveUserRelation :exec
+DELETE FROM user_relations
+WHERE user_id = $1
+AND target_id = $2
+AND kind = $3;
+
+-- name: ListUserRelations :many
+SELECT target_id, created_at FROM user_relations
+WHERE user_id = sqlc.arg(user_id)
+AND kind = sqlc.arg(kind)
+AND (sqlc.narg(after_created_at)::timestamp IS NULL OR (created_at, target_id) < (sqlc.narg(after_created_at), sqlc.narg(after_id)::uuid))
+ORDER BY created_at DESC, target_id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: IsBlockedBetween :one
+SELECT EXISTS (
+    SELECT 1 FROM user_relations
+    WHERE kind = 'block'
+    AND ((user_id = sqlc.arg(user_a) AND target_id = sqlc.arg(user_b))
+    OR (user_id = sqlc.arg(user_b) AND target_id = sqlc.arg(user_a)))
+) AS blocked;
+
+-- name: HiddenFromViewer :one
+SELECT hidden_from_viewer(sqlc.narg(viewer_id)::uuid, sqlc.arg(author_id)::uuid) AS hidden;
+
+-- name: RemoveFollowsBetween :exec
+DELETE FROM follows
+WHERE (follower_id = sqlc.arg(user_a) AND followee_id = sqlc.arg(user_b))
+OR (follower_id = sqlc.arg(user_b) AND followee_id = sqlc.arg(user_a));
+
+-- name: RemovedForViewer :one
+SELECT removed_for_viewer(sqlc.narg(viewer_id)::uuid, sqlc.arg(author_id)::uuid, sqlc.narg(hidden_at)::timestamp) AS removed;
@@ -0,0 +1,28 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN like_count INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN repost_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE likes(
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, chirp_id)
+);
+
+CREATE TABLE reposts(
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, chirp_id)
+);
+
+CREATE INDEX reposts_user_id_created_at_idx ON reposts (user_id, created_at);
+
+-- +goose Down
+DROP TABLE reposts;
+DROP TABLE likes;
+
+ALTER TABLE chirps
+DROP COLUMN repost_count,
+DROP COLUMN like_count;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE moderation_words(
+    word TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL
+);
+
+INSERT INTO moderation_words (word, created_at)
+VALUES ('kerfuffle', NOW()), ('sharbert', NOW()), ('fornax', NOW());
+
+CREATE TABLE moderation_flags(
+    id UUID PRIMARY KEY NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    resolved_at TIMESTAMP
+);
+
+CREATE INDEX moderation_flags_open_idx ON moderation_flags (created_at) WHERE resolved_at IS NULL;
+
+-- +goose Down
+DROP TABLE moderation_flags;
+DROP TABLE moderation_words;
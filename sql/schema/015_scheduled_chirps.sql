@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN publish_at TIMESTAMP,
+ADD COLUMN published_at TIMESTAMP;
+
+UPDATE chirps SET published_at = created_at;
+
+CREATE INDEX chirps_publish_at_idx ON chirps (publish_at) WHERE published_at IS NULL;
+CREATE INDEX chirps_unpublished_user_id_idx ON chirps (user_id) WHERE published_at IS NULL;
+
+-- +goose Down
+DROP INDEX chirps_unpublished_user_id_idx;
+DROP INDEX chirps_publish_at_idx;
+
+ALTER TABLE chirps
+DROP COLUMN published_at,
+DROP COLUMN publish_at;
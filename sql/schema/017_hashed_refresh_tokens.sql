@@ -0,0 +1,23 @@
+-- +goose Up
+-- Existing tokens are converted in place so nobody gets logged out. Parents
+-- are rewritten in the same statement, so the self-reference still holds
+-- when the foreign key is checked.
+UPDATE refresh_tokens
+SET token = encode(sha256(convert_to(token, 'UTF8')), 'hex'),
+parent_token = encode(sha256(convert_to(parent_token, 'UTF8')), 'hex');
+
+ALTER TABLE refresh_tokens
+RENAME COLUMN token TO token_hash;
+
+ALTER TABLE refresh_tokens
+RENAME COLUMN parent_token TO parent_token_hash;
+
+-- +goose Down
+-- Digests can't be turned back into tokens, so every session ends.
+DELETE FROM refresh_tokens;
+
+ALTER TABLE refresh_tokens
+RENAME COLUMN parent_token_hash TO parent_token;
+
+ALTER TABLE refresh_tokens
+RENAME COLUMN token_hash TO token;
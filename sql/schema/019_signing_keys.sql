@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE signing_keys(
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    private_key BYTEA NOT NULL,
+    retired_at TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE signing_keys;
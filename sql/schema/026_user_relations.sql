@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE user_relations(
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('block', 'mute')),
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, kind, target_id),
+    CHECK (user_id <> target_id)
+);
+
+CREATE INDEX user_relations_target_id_idx ON user_relations (target_id, kind);
+
+-- hidden_from_viewer is the one rule every feed applies: a viewer doesn't see
+-- chirps from someone they've blocked or muted, or from someone who has
+-- blocked them. Anonymous viewers see everything.
+-- +goose StatementBegin
+CREATE FUNCTION hidden_from_viewer(viewer_id UUID, author_id UUID) RETURNS BOOLEAN
+LANGUAGE SQL STABLE AS $$
+    SELECT viewer_id IS NOT NULL AND EXISTS (
+        SELECT 1 FROM user_relations
+        WHERE (user_relations.user_id = viewer_id AND user_relations.target_id = author_id)
+        OR (user_relations.user_id = author_id AND user_relations.target_id = viewer_id AND user_relations.kind = 'block')
+    )
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION hidden_from_viewer(UUID, UUID);
+DROP TABLE user_relations;
@@ -0,0 +1,78 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN hidden_at TIMESTAMP;
+
+ALTER TABLE users
+ADD COLUMN suspended_at TIMESTAMP;
+
+CREATE TABLE reports(
+    id UUID PRIMARY KEY NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    reporter_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chirp_id UUID REFERENCES chirps(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    assigned_to UUID REFERENCES users(id) ON DELETE SET NULL,
+    assigned_at TIMESTAMP,
+    resolution TEXT CHECK (resolution IN ('actioned', 'dismissed')),
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP
+);
+
+CREATE INDEX reports_open_idx ON reports (created_at, id) WHERE resolved_at IS NULL;
+CREATE INDEX reports_user_id_idx ON reports (user_id);
+
+-- The audit trail deliberately has no foreign keys, so it outlives the users
+-- and chirps it mentions, and the trigger keeps it append-only.
+CREATE TABLE moderation_audit_log(
+    id UUID PRIMARY KEY NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    actor_id UUID NOT NULL,
+    action TEXT NOT NULL,
+    target_type TEXT NOT NULL,
+    target_id TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX moderation_audit_log_created_at_idx ON moderation_audit_log (created_at, id);
+
+-- +goose StatementBegin
+CREATE FUNCTION moderation_audit_log_append_only() RETURNS TRIGGER
+LANGUAGE plpgsql AS $$
+BEGIN
+    RAISE EXCEPTION 'moderation_audit_log is append-only';
+END;
+$$;
+-- +goose StatementEnd
+
+CREATE TRIGGER moderation_audit_log_append_only
+BEFORE UPDATE OR DELETE ON moderation_audit_log
+FOR EACH ROW EXECUTE FUNCTION moderation_audit_log_append_only();
+
+-- removed_for_viewer hides chirps a moderator has taken down from everyone
+-- but their author and the moderators themselves.
+-- +goose StatementBegin
+CREATE FUNCTION removed_for_viewer(viewer_id UUID, author_id UUID, hidden_at TIMESTAMP) RETURNS BOOLEAN
+LANGUAGE SQL STABLE AS $$
+    SELECT hidden_at IS NOT NULL
+    AND viewer_id IS DISTINCT FROM author_id
+    AND NOT EXISTS (
+        SELECT 1 FROM users
+        WHERE users.id = viewer_id
+        AND users.role IN ('moderator', 'admin')
+    );
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION removed_for_viewer(UUID, UUID, TIMESTAMP);
+DROP TABLE moderation_audit_log;
+DROP FUNCTION moderation_audit_log_append_only();
+DROP TABLE reports;
+
+ALTER TABLE users
+DROP COLUMN suspended_at;
+
+ALTER TABLE chirps
+DROP COLUMN hidden_at;
@@ -0,0 +1,77 @@
+-- +goose Up
+CREATE TABLE suspensions(
+    id UUID PRIMARY KEY NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    issued_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT NOT NULL,
+    expires_at TIMESTAMP,
+    hide_chirps BOOLEAN NOT NULL DEFAULT FALSE,
+    lifted_at TIMESTAMP,
+    lifted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    appeal TEXT,
+    appealed_at TIMESTAMP
+);
+
+-- A user has at most one suspension in force. Expired ones are closed off by
+-- setting lifted_at, so they don't count.
+CREATE UNIQUE INDEX suspensions_active_idx ON suspensions (user_id) WHERE lifted_at IS NULL;
+CREATE INDEX suspensions_expires_at_idx ON suspensions (expires_at) WHERE lifted_at IS NULL;
+
+INSERT INTO suspensions (id, created_at, user_id, reason)
+SELECT gen_random_uuid(), suspended_at, id, ''
+FROM users
+WHERE suspended_at IS NOT NULL;
+
+ALTER TABLE users
+DROP COLUMN suspended_at;
+
+-- A suspended user's chirps can be hidden along with the account, under the
+-- same rules as a moderator taking a single chirp down.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION removed_for_viewer(viewer_id UUID, author_id UUID, hidden_at TIMESTAMP) RETURNS BOOLEAN
+LANGUAGE SQL STABLE AS $$
+    SELECT (
+        hidden_at IS NOT NULL
+        OR EXISTS (
+            SELECT 1 FROM suspensions
+            WHERE suspensions.user_id = author_id
+            AND suspensions.hide_chirps
+            AND suspensions.lifted_at IS NULL
+            AND (suspensions.expires_at IS NULL OR suspensions.expires_at > NOW())
+        )
+    )
+    AND viewer_id IS DISTINCT FROM author_id
+    AND NOT EXISTS (
+        SELECT 1 FROM users
+        WHERE users.id = viewer_id
+        AND users.role IN ('moderator', 'admin')
+    );
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION removed_for_viewer(viewer_id UUID, author_id UUID, hidden_at TIMESTAMP) RETURNS BOOLEAN
+LANGUAGE SQL STABLE AS $$
+    SELECT hidden_at IS NOT NULL
+    AND viewer_id IS DISTINCT FROM author_id
+    AND NOT EXISTS (
+        SELECT 1 FROM users
+        WHERE users.id = viewer_id
+        AND users.role IN ('moderator', 'admin')
+    );
+$$;
+-- +goose StatementEnd
+
+ALTER TABLE users
+ADD COLUMN suspended_at TIMESTAMP;
+
+UPDATE users
+SET suspended_at = suspensions.created_at
+FROM suspensions
+WHERE suspensions.user_id = users.id
+AND suspensions.lifted_at IS NULL
+AND (suspensions.expires_at IS NULL OR suspensions.expires_at > NOW());
+
+DROP TABLE suspensions;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Signing keys were stored as plain seeds. They can't be sealed here without
+-- SIGNING_KEY_SECRET, so they're dropped: the next server to start creates a
+-- sealed one, and access tokens the old keys signed stop working until
+-- clients refresh.
+DELETE FROM signing_keys;
+ALTER TABLE signing_keys RENAME COLUMN private_key TO sealed_private_key;
+
+-- +goose Down
+DELETE FROM signing_keys;
+ALTER TABLE signing_keys RENAME COLUMN sealed_private_key TO private_key;
@@ -0,0 +1,395 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+	"github.com/willthefoollearn/chirpy/internal/pagination"
+)
+
+const maxAppealLength = 2000
+
+type Suspension struct {
+	ID         uuid.UUID  `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UserID     uuid.UUID  `json:"user_id"`
+	IssuedBy   *uuid.UUID `json:"issued_by"`
+	Reason     string     `json:"reason"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	HideChirps bool       `json:"hide_chirps"`
+	LiftedAt   *time.Time `json:"lifted_at,omitempty"`
+	LiftedBy   *uuid.UUID `json:"lifted_by,omitempty"`
+	Appeal     string     `json:"appeal,omitempty"`
+	AppealedAt *time.Time `json:"appealed_at,omitempty"`
+}
+
+func databaseSuspensionToSuspension(suspension database.Suspension) Suspension {
+	converted := Suspension{
+		ID:         suspension.ID,
+		CreatedAt:  suspension.CreatedAt,
+		UserID:     suspension.UserID,
+		Reason:     suspension.Reason,
+		HideChirps: suspension.HideChirps,
+		Appeal:     suspension.Appeal.String,
+	}
+	if suspension.IssuedBy.Valid {
+		converted.IssuedBy = &suspension.IssuedBy.UUID
+	}
+	if suspension.ExpiresAt.Valid {
+		converted.ExpiresAt = &suspension.ExpiresAt.Time
+	}
+	if suspension.LiftedAt.Valid {
+		converted.LiftedAt = &suspension.LiftedAt.Time
+	}
+	if suspension.LiftedBy.Valid {
+		converted.LiftedBy = &suspension.LiftedBy.UUID
+	}
+	if suspension.AppealedAt.Valid {
+		converted.AppealedAt = &suspension.AppealedAt.Time
+	}
+
+	return converted
+}
+
+// checkNotSuspended refuses to start or extend a session for a user with a
+// suspension in force, telling them why and for how long. It writes the
+// error response itself.
+func checkNotSuspended(w http.ResponseWriter, req *http.Request, db *database.Queries, userID uuid.UUID) bool {
+	type suspendedResult struct {
+		Error     string     `json:"error"`
+		Reason    string     `json:"reason"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+
+	suspension, err := db.GetActiveSuspension(req.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return true
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to check account status", err)
+		return false
+	}
+
+	converted := databaseSuspensionToSuspension(suspension)
+	respondWithJson(w, http.StatusForbidden, suspendedResult{
+		Error:     "Account is suspended",
+		Reason:    converted.Reason,
+		ExpiresAt: converted.ExpiresAt,
+	})
+	return false
+}
+
+// handlerSuspendUser suspends a user until expires_at, or for good if it's
+// left out, replacing any suspension already in force. Every session and
+// personal access token is revoked and the token version bumped, so access
+// tokens already handed out stop validating too.
+func (cfg *apiConfig) handlerSuspendUser(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Reason     string     `json:"reason"`
+		ExpiresAt  *time.Time `json:"expires_at"`
+		HideChirps bool       `json:"hide_chirps"`
+	}
+
+	userID, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	reason := strings.TrimSpace(params.Reason)
+	if reason == "" {
+		respondWithError(w, http.StatusBadRequest, "A suspension needs a reason", nil)
+		return
+	}
+
+	expiresAt := sql.NullTime{}
+	if params.ExpiresAt != nil {
+		if !params.ExpiresAt.After(time.Now()) {
+			respondWithError(w, http.StatusBadRequest, "expires_at must be in the future", nil)
+			return
+		}
+		expiresAt = sql.NullTime{Time: params.ExpiresAt.UTC(), Valid: true}
+	}
+
+	admin := adminFromContext(req.Context())
+	if userID == admin {
+		respondWithError(w, http.StatusBadRequest, "Admins can't suspend themselves", nil)
+		return
+	}
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to suspend user", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	_, err = qtx.GetUser(req.Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Unable to find user", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to suspend user", err)
+		return
+	}
+
+	_, err = qtx.LiftSuspension(req.Context(), database.LiftSuspensionParams{
+		UserID:   userID,
+		LiftedBy: uuid.NullUUID{UUID: admin, Valid: true},
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusInternalServerError, "Unable to suspend user", err)
+		return
+	}
+
+	suspension, err := qtx.CreateSuspension(req.Context(), database.CreateSuspensionParams{
+		UserID:     userID,
+		IssuedBy:   uuid.NullUUID{UUID: admin, Valid: true},
+		Reason:     reason,
+		ExpiresAt:  expiresAt,
+		HideChirps: params.HideChirps,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to suspend user", err)
+		return
+	}
+
+	_, err = qtx.IncrementTokenVersion(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to suspend user", err)
+		return
+	}
+
+	err = qtx.RevokeAllUserSessions(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to suspend user", err)
+		return
+	}
+
+	err = qtx.RevokeAllPersonalAccessTokens(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to suspend user", err)
+		return
+	}
+
+	err = recordAudit(req.Context(), qtx, admin, "user.suspend", "user", userID.String(), reason)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to suspend user", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to suspend user", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusCreated, databaseSuspensionToSuspension(suspension))
+}
+
+func (cfg *apiConfig) handlerUnsuspendUser(w http.ResponseWriter, req *http.Request) {
+	userID, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Can't parse path", err)
+		return
+	}
+
+	admin := adminFromContext(req.Context())
+
+	tx, err := cfg.conn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to lift suspension", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	suspension, err := qtx.LiftSuspension(req.Context(), database.LiftSuspensionParams{
+		UserID:   userID,
+		LiftedBy: uuid.NullUUID{UUID: admin, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "User isn't suspended", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to lift suspension", err)
+		return
+	}
+
+	err = recordAudit(req.Context(), qtx, admin, "user.unsuspend", "user", userID.String(), "")
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to lift suspension", err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to lift suspension", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, databaseSuspensionToSuspension(suspension))
+}
+
+// handlerListSuspensions lists suspensions in force, oldest first.
+// ?appealed=true narrows it to the ones waiting on an appeal.
+func (cfg *apiConfig) handlerListSuspensions(w http.ResponseWriter, req *http.Request) {
+	page, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid pagination parameters", err)
+		return
+	}
+
+	suspensions, err := cfg.db.ListActiveSuspensions(req.Context(), database.ListActiveSuspensionsParams{
+		AppealedOnly:   req.URL.Query().Get("appealed") == "true",
+		AfterCreatedAt: page.AfterCreatedAt,
+		AfterID:        page.AfterID,
+		RowLimit:       page.Limit + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Can't retrieve suspensions", err)
+		return
+	}
+
+	if len(suspensions) > int(page.Limit) {
+		suspensions = suspensions[:page.Limit]
+		last := suspensions[len(suspensions)-1]
+		setNextPageLink(w, req, pagination.Cursor{
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+		})
+	}
+
+	convertedSuspensions := []Suspension{}
+	for _, suspension := range suspensions {
+		convertedSuspensions = append(convertedSuspensions, databaseSuspensionToSuspension(suspension))
+	}
+
+	respondWithJson(w, http.StatusOK, convertedSuspensions)
+}
+
+// handlerAppealSuspension lets a suspended user contest their suspension,
+// once. They can't log in, so it takes their email and password directly,
+// under the same throttling as a login.
+func (cfg *apiConfig) handlerAppealSuspension(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+		Appeal   string `json:"appeal"`
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	appeal := strings.TrimSpace(params.Appeal)
+	if appeal == "" {
+		respondWithError(w, http.StatusBadRequest, "An appeal can't be empty", nil)
+		return
+	}
+	if utf8.RuneCountInString(appeal) > maxAppealLength {
+		respondWithError(w, http.StatusBadRequest, "Appeal is too long", nil)
+		return
+	}
+
+	throttle := newLoginThrottle(req, params.Email)
+	lockedUntil, err := throttle.begin(req.Context(), cfg.conn, cfg.db)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to appeal suspension", err)
+		return
+	}
+	if !lockedUntil.IsZero() {
+		respondWithLoginLocked(w, lockedUntil)
+		return
+	}
+
+	user, err := cfg.db.LookUpUser(req.Context(), params.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = auth.CheckNoPassword(params.Password)
+	} else if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to appeal suspension", err)
+		return
+	} else {
+		err = auth.CheckPasswordHash(params.Password, user.HashedPassword)
+	}
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Incorrect email or password", err)
+		return
+	}
+
+	suspension, err := cfg.db.GetActiveSuspension(req.Context(), user.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Account isn't suspended", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to appeal suspension", err)
+		return
+	}
+
+	// The password alone never clears the account's failures, or appeals
+	// could be used to reset the count of wrong two-factor codes. Only this
+	// attempt comes back off the address.
+	err = throttle.forgiveAddress(req.Context(), cfg.db)
+	if err != nil {
+		log.Printf("Unable to clear failed logins: %v", err)
+	}
+
+	suspension, err = cfg.db.AppealSuspension(req.Context(), database.AppealSuspensionParams{
+		ID:     suspension.ID,
+		Appeal: sql.NullString{String: appeal, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusConflict, "Suspension has already been appealed", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to appeal suspension", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, databaseSuspensionToSuspension(suspension))
+}
+
+// liftExpiredSuspensions closes off suspensions once they run out. They stop
+// applying at expires_at regardless; this just records that they're over.
+func (cfg *apiConfig) liftExpiredSuspensions(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		lifted, err := cfg.db.LiftExpiredSuspensions(context.Background())
+		if err != nil {
+			log.Printf("Unable to lift expired suspensions: %v", err)
+			continue
+		}
+		for _, suspension := range lifted {
+			log.Printf("Suspension %s of user %s has expired", suspension.ID, suspension.UserID)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+)
+
+const accessTokenLifetime = time.Hour
+
+// keyReloadInterval is the least time between reloads triggered by tokens
+// signed with an unknown key, since anyone can make one up.
+const keyReloadInterval = 10 * time.Second
+
+// setupSigningKeys builds the keyring access tokens are signed with. The keys
+// live in the signing_keys table so every server instance shares them; the
+// first instance to start against an empty table creates one. They're sealed
+// with SIGNING_KEY_SECRET, which every instance needs. JWT_ISSUER and
+// JWT_AUDIENCE set the iss and aud claims.
+func (cfg *apiConfig) setupSigningKeys(ctx context.Context) error {
+	secret := os.Getenv("SIGNING_KEY_SECRET")
+	if secret == "" {
+		return errors.New("SIGNING_KEY_SECRET must be set")
+	}
+	sealer, err := auth.NewKeySealer(secret)
+	if err != nil {
+		return err
+	}
+	cfg.keySealer = sealer
+
+	issuer := os.Getenv("JWT_ISSUER")
+	if issuer == "" {
+		issuer = "chirpy"
+	}
+	audience := os.Getenv("JWT_AUDIENCE")
+	if audience == "" {
+		audience = "chirpy-api"
+	}
+	cfg.keyring = auth.NewKeyring(issuer, audience)
+
+	keys, err := cfg.db.ListSigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return cfg.rotateSigningKey(ctx)
+	}
+
+	return cfg.setSigningKeys(keys)
+}
+
+// setSigningKeys unseals keys into the keyring. If any of them can't be
+// unsealed, most likely because SIGNING_KEY_SECRET differs between instances,
+// the keyring is left as it was.
+func (cfg *apiConfig) setSigningKeys(keys []database.SigningKey) error {
+	ring := make([]auth.Key, 0, len(keys))
+	for _, key := range keys {
+		seed, err := cfg.keySealer.Open(key.ID, key.SealedPrivateKey)
+		if err != nil {
+			return fmt.Errorf("signing key %s: %w", key.ID, err)
+		}
+
+		ring = append(ring, auth.Key{
+			ID:         key.ID,
+			CreatedAt:  key.CreatedAt,
+			PrivateKey: ed25519.NewKeyFromSeed(seed),
+			Retired:    key.RetiredAt.Valid,
+		})
+	}
+	cfg.keyring.SetKeys(ring)
+	return nil
+}
+
+// reloadSigningKeys picks up keys rotated by other server instances.
+func (cfg *apiConfig) reloadSigningKeys(ctx context.Context) error {
+	keys, err := cfg.db.ListSigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	return cfg.setSigningKeys(keys)
+}
+
+// reloadUnknownSigningKeys reloads the keys for a token signed by a key this
+// instance hasn't seen, unless that was done within keyReloadInterval. Callers
+// that arrive while a reload is running wait for it and then use its result.
+func (cfg *apiConfig) reloadUnknownSigningKeys(ctx context.Context) error {
+	cfg.keyReloadMu.Lock()
+	defer cfg.keyReloadMu.Unlock()
+
+	if time.Since(cfg.lastKeyReload) < keyReloadInterval {
+		return nil
+	}
+	cfg.lastKeyReload = time.Now()
+
+	return cfg.reloadSigningKeys(ctx)
+}
+
+func (cfg *apiConfig) watchSigningKeys(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		err := cfg.reloadSigningKeys(context.Background())
+		if err != nil {
+			log.Printf("Unable to reload signing keys: %v", err)
+		}
+	}
+}
+
+// rotateSigningKey retires the active key in favour of a new one. Retired keys
+// keep verifying for a day, well past the lifetime of any access token they
+// signed, and are dropped after that.
+func (cfg *apiConfig) rotateSigningKey(ctx context.Context) error {
+	key, err := auth.GenerateKey()
+	if err != nil {
+		return err
+	}
+
+	tx, err := cfg.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	err = qtx.RetireSigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = qtx.CreateSigningKey(ctx, database.CreateSigningKeyParams{
+		ID:               key.ID,
+		CreatedAt:        key.CreatedAt,
+		SealedPrivateKey: cfg.keySealer.Seal(key.ID, key.PrivateKey.Seed()),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = qtx.DeleteRetiredSigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return cfg.reloadSigningKeys(ctx)
+}
+
+// makeJWT issues a full-access token for a session under the user's current
+// token version.
+func (cfg *apiConfig) makeJWT(userID, sessionID uuid.UUID, tokenVersion int32) (string, error) {
+	return cfg.keyring.MakeJWT(userID, sessionID, tokenVersion, auth.AllScopes, accessTokenLifetime)
+}
+
+// authenticate works out who a bearer token speaks for. Personal access
+// tokens are looked up by digest; anything else has to be an access JWT
+// signed by the keyring and issued under the user's current token version. A
+// JWT signed by a key this instance hasn't seen yet triggers a reload, at most
+// once every keyReloadInterval, since another instance may just have rotated.
+func (cfg *apiConfig) authenticate(ctx context.Context, tokenString string) (auth.Principal, error) {
+	if auth.IsPersonalAccessToken(tokenString) {
+		token, err := cfg.db.UsePersonalAccessToken(ctx, auth.HashToken(tokenString))
+		if err != nil {
+			return auth.Principal{}, err
+		}
+
+		return auth.Principal{
+			UserID: token.UserID,
+			Scopes: token.Scopes,
+		}, nil
+	}
+
+	currentVersion := func(userID uuid.UUID) (int32, error) {
+		return cfg.db.GetUserTokenVersion(ctx, userID)
+	}
+
+	principal, err := cfg.keyring.ValidateJWT(tokenString, currentVersion)
+	if errors.Is(err, auth.ErrUnknownKey) {
+		reloadErr := cfg.reloadUnknownSigningKeys(ctx)
+		if reloadErr != nil {
+			return auth.Principal{}, reloadErr
+		}
+		return cfg.keyring.ValidateJWT(tokenString, currentVersion)
+	}
+	return principal, err
+}
+
+// authorize is authenticate for routes that change something, which also
+// need the token to carry the given scope.
+func (cfg *apiConfig) authorize(ctx context.Context, tokenString, scope string) (uuid.UUID, error) {
+	principal, err := cfg.authenticate(ctx, tokenString)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	err = principal.Require(scope)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return principal.UserID, nil
+}
+
+// respondWithAuthError reports a failed authenticate or authorize call: a
+// valid token without the right scope or role is forbidden, anything else is
+// unauthorized.
+func respondWithAuthError(w http.ResponseWriter, msg string, err error) {
+	if errors.Is(err, auth.ErrInsufficientScope) || errors.Is(err, auth.ErrInsufficientRole) {
+		respondWithError(w, http.StatusForbidden, "Token doesn't allow this action", err)
+		return
+	}
+	respondWithError(w, http.StatusUnauthorized, msg, err)
+}
+
+func (cfg *apiConfig) handlerJWKS(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	respondWithJson(w, http.StatusOK, cfg.keyring.JWKS())
+}
+
+func (cfg *apiConfig) handlerRotateSigningKeys(w http.ResponseWriter, req *http.Request) {
+	err := cfg.rotateSigningKey(req.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unable to rotate signing keys", err)
+		return
+	}
+
+	respondWithJson(w, http.StatusOK, cfg.keyring.JWKS())
+}
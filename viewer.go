@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/willthefoollearn/chirpy/internal/auth"
+	"github.com/willthefoollearn/chirpy/internal/database"
+)
+
+// viewerID returns the user behind the request's access token, if any. Public
+// endpoints use it to personalise responses, so a missing or invalid token
+// just means an anonymous viewer.
+func (cfg *apiConfig) viewerID(req *http.Request) uuid.NullUUID {
+	accessToken, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		return uuid.NullUUID{}
+	}
+
+	principal, err := cfg.authenticate(req.Context(), accessToken)
+	if err != nil {
+		return uuid.NullUUID{}
+	}
+
+	return uuid.NullUUID{UUID: principal.UserID, Valid: true}
+}
+
+// decorateChirps fills in the parts of a chirp that live outside the chirps
+// table: its author, its media attachments and the viewer's own likes and
+// reposts.
+func (cfg *apiConfig) decorateChirps(ctx context.Context, viewer uuid.NullUUID, chirps []*Chirp) error {
+	err := cfg.setChirpAuthors(ctx, chirps)
+	if err != nil {
+		return err
+	}
+
+	err = cfg.setChirpMedia(ctx, chirps)
+	if err != nil {
+		return err
+	}
+
+	return cfg.setViewerState(ctx, viewer, chirps)
+}
+
+// setViewerState marks which of the chirps the viewer has liked or reposted.
+func (cfg *apiConfig) setViewerState(ctx context.Context, viewer uuid.NullUUID, chirps []*Chirp) error {
+	if !viewer.Valid || len(chirps) == 0 {
+		return nil
+	}
+
+	chirpIDs := make([]uuid.UUID, 0, len(chirps))
+	for _, chirp := range chirps {
+		chirpIDs = append(chirpIDs, chirp.ID)
+	}
+
+	liked, err := cfg.db.GetViewerLikes(ctx, database.GetViewerLikesParams{
+		UserID:   viewer.UUID,
+		ChirpIds: chirpIDs,
+	})
+	if err != nil {
+		return err
+	}
+
+	reposted, err := cfg.db.GetViewerReposts(ctx, database.GetViewerRepostsParams{
+		UserID:   viewer.UUID,
+		ChirpIds: chirpIDs,
+	})
+	if err != nil {
+		return err
+	}
+
+	likedSet := make(map[uuid.UUID]bool, len(liked))
+	for _, id := range liked {
+		likedSet[id] = true
+	}
+	repostedSet := make(map[uuid.UUID]bool, len(reposted))
+	for _, id := range reposted {
+		repostedSet[id] = true
+	}
+
+	for _, chirp := range chirps {
+		chirp.ViewerLiked = likedSet[chirp.ID]
+		chirp.ViewerReposted = repostedSet[chirp.ID]
+	}
+
+	return nil
+}
+
+// chirpHiddenFromViewer applies the feed visibility rules to a single chirp:
+// blocks and mutes between the viewer and its author, and moderator
+// takedowns.
+func (cfg *apiConfig) chirpHiddenFromViewer(ctx context.Context, viewer uuid.NullUUID, chirp database.Chirp) (bool, error) {
+	hidden, err := cfg.db.HiddenFromViewer(ctx, database.HiddenFromViewerParams{
+		ViewerID: viewer,
+		AuthorID: chirp.UserID,
+	})
+	if err != nil || hidden {
+		return hidden, err
+	}
+
+	return cfg.db.RemovedForViewer(ctx, database.RemovedForViewerParams{
+		ViewerID: viewer,
+		AuthorID: chirp.UserID,
+		HiddenAt: chirp.HiddenAt,
+	})
+}